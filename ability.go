@@ -0,0 +1,204 @@
+package dvorak
+
+import (
+	"regexp"
+	"strings"
+)
+
+// An AbilityKind classifies an Ability.
+type AbilityKind int
+
+const (
+	// StaticAbility is an effect that is not labeled or triggered,
+	// such as the effect of an Action card.
+	StaticAbility AbilityKind = iota
+
+	// ActionAbility is an effect labeled "Action:",
+	// which usually costs the player's action for the turn.
+	ActionAbility
+
+	// ThingAbility is an effect labeled "Thing:".
+	ThingAbility
+
+	// TriggeredAbility is an effect that occurs when a condition is met,
+	// such as "When this is destroyed" or "At the start of your turn".
+	TriggeredAbility
+)
+
+func (k AbilityKind) String() string {
+	switch k {
+	case StaticAbility:
+		return "static"
+	case ActionAbility:
+		return "action"
+	case ThingAbility:
+		return "thing"
+	case TriggeredAbility:
+		return "triggered"
+	default:
+		return "unknown"
+	}
+}
+
+// An Ability is a clause of a card's rule text.
+type Ability struct {
+	Kind AbilityKind
+
+	// Trigger is the condition of a TriggeredAbility,
+	// such as "At the start of your turn".
+	Trigger string
+
+	// Effect is the text of the ability following any label or trigger.
+	Effect string
+}
+
+// abilityLabel matches the labels that begin an ability: "Action:" or
+// "Thing:" at the start of a line or of a sentence. Its first submatch
+// is the label's word.
+var abilityLabel = regexp.MustCompile(`(?i)(?:^|[.!?]\s+)(action|thing)\s*:\s*`)
+
+// sentenceEnd matches the end of a sentence: its final punctuation,
+// any closing quotes or brackets, and the following space.
+var sentenceEnd = regexp.MustCompile(`[.!?]["')\]]*\s+`)
+
+// triggerPrefix matches the beginning of a triggered ability's condition.
+var triggerPrefix = regexp.MustCompile(`(?i)^(when|whenever|at the (start|beginning|end) of|each time|if this)\b`)
+
+// Abilities returns the clauses of the card's rule text.
+//
+// Each line of the text is split before each "Action:" or "Thing:" label
+// that begins the line or a sentence, and each labeled clause continues
+// to the next label. Text before the first label is split into sentences.
+// An unlabeled sentence that begins with a condition such as "When" or
+// "At the start of" followed by a comma is a TriggeredAbility, and other
+// unlabeled sentences are StaticAbilities.
+func (c Card) Abilities() []Ability {
+	var abilities []Ability
+	for _, line := range strings.Split(c.PlainText(), "\n") {
+		locs := abilityLabel.FindAllStringSubmatchIndex(line, -1)
+		// Each label's clause begins at its word, loc[2], so that the
+		// preceding clause keeps the end of its sentence.
+		if len(locs) == 0 || locs[0][2] > 0 {
+			end := len(line)
+			if len(locs) > 0 {
+				end = locs[0][2]
+			}
+			for _, sentence := range sentences(line[:end]) {
+				if a, ok := unlabeledAbility(sentence); ok {
+					abilities = append(abilities, a)
+				}
+			}
+		}
+		for i, loc := range locs {
+			end := len(line)
+			if i+1 < len(locs) {
+				end = locs[i+1][2]
+			}
+			kind := ActionAbility
+			if strings.EqualFold(line[loc[2]:loc[3]], "thing") {
+				kind = ThingAbility
+			}
+			abilities = append(abilities, Ability{
+				Kind:   kind,
+				Effect: strings.TrimSpace(line[loc[1]:end]),
+			})
+		}
+	}
+	return abilities
+}
+
+// sentences splits s after the end of each sentence.
+func sentences(s string) []string {
+	var ss []string
+	start := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(s, -1) {
+		ss = append(ss, s[start:loc[1]])
+		start = loc[1]
+	}
+	return append(ss, s[start:])
+}
+
+// unlabeledAbility returns the triggered or static ability in s.
+// It returns false if s is blank.
+func unlabeledAbility(s string) (Ability, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Ability{}, false
+	}
+	if triggerPrefix.MatchString(s) {
+		if cond, effect, ok := strings.Cut(s, ","); ok {
+			return Ability{
+				Kind:    TriggeredAbility,
+				Trigger: strings.TrimSpace(cond),
+				Effect:  strings.TrimSpace(effect),
+			}, true
+		}
+	}
+	return Ability{Kind: StaticAbility, Effect: s}, true
+}
+
+// keywords maps inflected forms of the recognized rule text keywords
+// to their base forms.
+var keywords = map[string]string{
+	"destroy": "destroy", "destroys": "destroy", "destroyed": "destroy",
+	"draw": "draw", "draws": "draw", "drew": "draw", "drawn": "draw",
+	"discard": "discard", "discards": "discard", "discarded": "discard",
+	"steal": "steal", "steals": "steal", "stole": "steal", "stolen": "steal",
+	"control": "control", "controls": "control",
+	"play": "play", "plays": "play", "played": "play",
+	"return": "return", "returns": "return", "returned": "return",
+	"shuffle": "shuffle", "shuffles": "shuffle", "shuffled": "shuffle",
+	"skip": "skip", "skips": "skip", "skipped": "skip",
+	"reveal": "reveal", "reveals": "reveal", "revealed": "reveal",
+	"search": "search", "searches": "search", "searched": "search",
+	"counter": "counter", "counters": "counter", "countered": "counter",
+	"copy": "copy", "copies": "copy", "copied": "copy",
+	"swap": "swap", "swaps": "swap", "swapped": "swap",
+	"exchange": "exchange", "exchanges": "exchange", "exchanged": "exchange",
+	"sacrifice": "sacrifice", "sacrifices": "sacrifice", "sacrificed": "sacrifice",
+	"win": "win", "wins": "win", "won": "win",
+	"lose": "lose", "loses": "lose", "lost": "lose",
+	"give": "give", "gives": "give", "gave": "give", "given": "give",
+	"take": "take", "takes": "take", "took": "take", "taken": "take",
+	"look": "look", "looks": "look", "looked": "look",
+	"choose": "choose", "chooses": "choose", "chose": "choose", "chosen": "choose",
+	"random": "random", "randomly": "random",
+	"target": "target", "targets": "target", "targeted": "target",
+}
+
+// wordPattern matches a word of rule text.
+var wordPattern = regexp.MustCompile(`[A-Za-z]+`)
+
+// Keywords returns the base forms of the recognized keywords in the card's
+// rule text, such as "destroy" and "draw", in order of first appearance.
+func (c Card) Keywords() []string {
+	var kws []string
+	seen := make(map[string]bool)
+	for _, w := range wordPattern.FindAllString(c.PlainText(), -1) {
+		kw, ok := keywords[strings.ToLower(w)]
+		if ok && !seen[kw] {
+			seen[kw] = true
+			kws = append(kws, kw)
+		}
+	}
+	return kws
+}
+
+// References returns the card supertypes that the card's rule text refers to,
+// in order of first appearance. Ability labels are not references.
+func (c Card) References() []string {
+	var refs []string
+	seen := make(map[string]bool)
+	text := abilityLabel.ReplaceAllString(c.PlainText(), " ")
+	for _, w := range wordPattern.FindAllString(text, -1) {
+		st := supertype(w)
+		if st == "" && (strings.HasSuffix(w, "s") || strings.HasSuffix(w, "S")) {
+			st = supertype(w[:len(w)-1])
+		}
+		if st != "" && !seen[st] {
+			seen[st] = true
+			refs = append(refs, st)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,124 @@
+package dvorak
+
+import (
+	"testing"
+
+	"kr.dev/diff"
+)
+
+func TestAbilities(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want []Ability
+	}{
+		{"", nil},
+		{"Draw a card.", []Ability{{Kind: StaticAbility, Effect: "Draw a card."}}},
+		{
+			"'''Action:''' Destroy target Thing.",
+			[]Ability{{Kind: ActionAbility, Effect: "Destroy target Thing."}},
+		},
+		{
+			"<b>Action:</b> Draw a card. <b>Thing:</b> Discard a card.",
+			[]Ability{
+				{Kind: ActionAbility, Effect: "Draw a card."},
+				{Kind: ThingAbility, Effect: "Discard a card."},
+			},
+		},
+		{
+			"Players may not draw cards.<br>At the start of your turn, steal a Thing.<br>When this is destroyed, draw two cards.",
+			[]Ability{
+				{Kind: StaticAbility, Effect: "Players may not draw cards."},
+				{Kind: TriggeredAbility, Trigger: "At the start of your turn", Effect: "steal a Thing."},
+				{Kind: TriggeredAbility, Trigger: "When this is destroyed", Effect: "draw two cards."},
+			},
+		},
+		{
+			"You have an extra hand size. '''Action:''' Draw a card.",
+			[]Ability{
+				{Kind: StaticAbility, Effect: "You have an extra hand size."},
+				{Kind: ActionAbility, Effect: "Draw a card."},
+			},
+		},
+		{
+			"Steal target thing: it is yours.",
+			[]Ability{{Kind: StaticAbility, Effect: "Steal target thing: it is yours."}},
+		},
+		{
+			"Draw a card! Thing: Discard a card.\nAction: Skip a turn.",
+			[]Ability{
+				{Kind: StaticAbility, Effect: "Draw a card!"},
+				{Kind: ThingAbility, Effect: "Discard a card."},
+				{Kind: ActionAbility, Effect: "Skip a turn."},
+			},
+		},
+		{
+			"Draw a card. When this is destroyed, steal a Thing.",
+			[]Ability{
+				{Kind: StaticAbility, Effect: "Draw a card."},
+				{Kind: TriggeredAbility, Trigger: "When this is destroyed", Effect: "steal a Thing."},
+			},
+		},
+		{
+			`Say "Fish!" Whenever a card is drawn, discard it. '''Action:''' Draw a card. Discard a card.`,
+			[]Ability{
+				{Kind: StaticAbility, Effect: `Say "Fish!"`},
+				{Kind: TriggeredAbility, Trigger: "Whenever a card is drawn", Effect: "discard it."},
+				{Kind: ActionAbility, Effect: "Draw a card. Discard a card."},
+			},
+		},
+		{
+			"When in doubt do nothing.",
+			[]Ability{{Kind: StaticAbility, Effect: "When in doubt do nothing."}},
+		},
+	} {
+		c := Card{Text: parseWikitext(tt.text)}
+		diff.Test(t, t.Errorf, c.Abilities(), tt.want)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"Nothing happens.", nil},
+		{"Destroy target Thing. Draw a card.", []string{"destroy", "target", "draw"}},
+		{"When this is destroyed, its owner draws two cards and destroys another.", []string{"destroy", "draw"}},
+		{"Gain control of a fish. Steal it.", []string{"control", "steal"}},
+	} {
+		c := Card{Text: parseWikitext(tt.text)}
+		diff.Test(t, t.Errorf, c.Keywords(), tt.want)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"Draw a card.", nil},
+		{"'''Action:''' Destroy target Thing.", []string{"Thing"}},
+		{"Counter an Action. Steal all Things.", []string{"Action", "Thing"}},
+		{"Actions cost nothing.", []string{"Action"}},
+		{"Steal target thing: it is yours.", []string{"Thing"}},
+	} {
+		c := Card{Text: parseWikitext(tt.text)}
+		diff.Test(t, t.Errorf, c.References(), tt.want)
+	}
+}
+
+func TestAbilityKindString(t *testing.T) {
+	for k, want := range map[AbilityKind]string{
+		StaticAbility:    "static",
+		ActionAbility:    "action",
+		ThingAbility:     "thing",
+		TriggeredAbility: "triggered",
+		AbilityKind(-1):  "unknown",
+	} {
+		if got := k.String(); got != want {
+			t.Errorf("AbilityKind(%d).String(): got %q, want %q", int(k), got, want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package dvorak
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// queryAPI sends a request with params to the MediaWiki API
+// and stores the JSON result in format version 2 in v.
+func (c *Client) queryAPI(params url.Values, v interface{}) error {
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+	b, err := c.readPage(c.apiRequestURL(params))
+	if err != nil {
+		return err
+	}
+	var e struct {
+		Error *struct {
+			Code string
+			Info string
+		}
+	}
+	if err := json.Unmarshal(b, &e); err != nil {
+		return fmt.Errorf("api.php: %v", err)
+	}
+	if e.Error != nil {
+		return &APIError{Code: e.Error.Code, Info: e.Error.Info}
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,193 @@
+// Package art prepares card images for printing and rendering.
+//
+// Process decodes a downloaded card image in PNG, GIF, or JPEG format,
+// scales it to fit or fill a card's art box, composites it over the card's
+// ImgBack color, and encodes the result as a PNG image that records its
+// resolution, so that every card's art has the same size and format.
+// It uses only the standard library's image packages.
+package art
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"  // register the GIF format
+	_ "image/jpeg" // register the JPEG format
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+// A Mode is a way of scaling an image to a target size.
+type Mode int
+
+const (
+	// Contain scales an image to fit within the target size,
+	// leaving a margin of the background color on two sides
+	// if the aspect ratios differ.
+	Contain Mode = iota
+
+	// Cover scales an image to cover the target size,
+	// cropping its center if the aspect ratios differ.
+	Cover
+)
+
+// DefaultBackground is the background color of an image
+// whose card has no ImgBack.
+const DefaultBackground dvorak.Color = "FFF"
+
+// Options configure Process.
+type Options struct {
+	// Width and Height are the size of the output image in pixels.
+	Width, Height int
+
+	// DPI is the resolution of the output image in dots per inch,
+	// recorded in its metadata for printing. If 0, none is recorded.
+	DPI int
+
+	Mode Mode
+}
+
+// Size returns the number of pixels that span the given length
+// in inches at dpi dots per inch.
+func Size(inches float64, dpi int) int {
+	return int(math.Round(inches * float64(dpi)))
+}
+
+// Process reads an image from r, fits it to the size given by opts
+// over the background color back, and writes the result to w as a PNG
+// image. If back is empty, DefaultBackground is used.
+func Process(w io.Writer, r io.Reader, back dvorak.Color, opts Options) error {
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return err
+	}
+	dst, err := Fit(src, back, opts.Width, opts.Height, opts.Mode)
+	if err != nil {
+		return err
+	}
+	return Encode(w, dst, opts.DPI)
+}
+
+// Fit returns src scaled to width by height pixels according to mode
+// and composited over the background color back.
+// If back is empty, DefaultBackground is used.
+func Fit(src image.Image, back dvorak.Color, width, height int, mode Mode) (*image.RGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid size %dx%d", width, height)
+	}
+	if back == "" {
+		back = DefaultBackground
+	}
+	r, g, b := back.RGB()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.RGBA{r, g, b, 0xff}), image.Point{}, draw.Src)
+
+	sb := src.Bounds()
+	if sb.Empty() {
+		return dst, nil
+	}
+	sw, sh := float64(sb.Dx()), float64(sb.Dy())
+	scale := math.Min(float64(width)/sw, float64(height)/sh)
+	if mode == Cover {
+		scale = math.Max(float64(width)/sw, float64(height)/sh)
+	}
+	// The scaled image is centered in dst and clipped to it.
+	w, h := sw*scale, sh*scale
+	x0, y0 := (float64(width)-w)/2, (float64(height)-h)/2
+	rect := image.Rect(
+		int(math.Round(x0)), int(math.Round(y0)),
+		int(math.Round(x0+w)), int(math.Round(y0+h)),
+	).Intersect(dst.Bounds())
+
+	scaled := image.NewRGBA(rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			// The source region that maps to the destination pixel.
+			fx0 := float64(sb.Min.X) + (float64(x)-x0)/scale
+			fy0 := float64(sb.Min.Y) + (float64(y)-y0)/scale
+			scaled.SetRGBA(x, y, average(src, fx0, fy0, fx0+1/scale, fy0+1/scale))
+		}
+	}
+	draw.Draw(dst, rect, scaled, rect.Min, draw.Over)
+	return dst, nil
+}
+
+// average returns the mean premultiplied color of the pixels of src whose
+// centers lie within the rectangle from (x0, y0) to (x1, y1), or of the
+// nearest pixel if there are none.
+func average(src image.Image, x0, y0, x1, y1 float64) color.RGBA {
+	b := src.Bounds()
+	ix0, iy0 := clamp(int(math.Ceil(x0-0.5)), b.Min.X, b.Max.X-1), clamp(int(math.Ceil(y0-0.5)), b.Min.Y, b.Max.Y-1)
+	ix1, iy1 := clamp(int(math.Ceil(x1-0.5)), ix0+1, b.Max.X), clamp(int(math.Ceil(y1-0.5)), iy0+1, b.Max.Y)
+	var r, g, bl, a, n uint64
+	for y := iy0; y < iy1; y++ {
+		for x := ix0; x < ix1; x++ {
+			cr, cg, cb, ca := src.At(x, y).RGBA()
+			r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+			n++
+		}
+	}
+	return color.RGBA{
+		R: uint8(r / n >> 8),
+		G: uint8(g / n >> 8),
+		B: uint8(bl / n >> 8),
+		A: uint8(a / n >> 8),
+	}
+}
+
+// clamp returns v limited to the range [lo, hi].
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// Encode writes img to w as a PNG image with a resolution of dpi dots
+// per inch. If dpi is 0, no resolution is recorded.
+func Encode(w io.Writer, img image.Image, dpi int) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	b := buf.Bytes()
+	if dpi > 0 {
+		b = withPHYs(b, dpi)
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// pngHeaderLen is the length of the PNG signature and IHDR chunk,
+// which precede every other chunk.
+const pngHeaderLen = 8 + 4 + 4 + 13 + 4
+
+// withPHYs returns the PNG image b with a pHYs chunk recording a
+// resolution of dpi dots per inch inserted after its IHDR chunk.
+// https://www.w3.org/TR/png/#11pHYs
+func withPHYs(b []byte, dpi int) []byte {
+	// pHYs records pixels per meter.
+	ppm := uint32(math.Round(float64(dpi) / 0.0254))
+	chunk := make([]byte, 4+4+9+4)
+	binary.BigEndian.PutUint32(chunk[0:], 9)
+	copy(chunk[4:], "pHYs")
+	binary.BigEndian.PutUint32(chunk[8:], ppm)
+	binary.BigEndian.PutUint32(chunk[12:], ppm)
+	chunk[16] = 1 // the unit is the meter
+	binary.BigEndian.PutUint32(chunk[17:], crc32.ChecksumIEEE(chunk[4:17]))
+
+	out := make([]byte, 0, len(b)+len(chunk))
+	out = append(out, b[:pngHeaderLen]...)
+	out = append(out, chunk...)
+	return append(out, b[pngHeaderLen:]...)
+}
@@ -0,0 +1,152 @@
+package art
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+var (
+	red   = color.RGBA{0xff, 0, 0, 0xff}
+	blue  = color.RGBA{0, 0, 0xff, 0xff}
+	white = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// uniform returns a w by h image of color c.
+func uniform(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// rows returns the color of the first pixel of each row of img.
+func rows(img *image.RGBA) []color.RGBA {
+	var c []color.RGBA
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		c = append(c, img.RGBAAt(0, y))
+	}
+	return c
+}
+
+func TestFit(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		src  image.Image
+		back string
+		w, h int
+		mode Mode
+		want []color.RGBA
+	}{
+		{"contain", uniform(2, 1, red), "00F", 4, 4, Contain, []color.RGBA{blue, red, red, blue}},
+		{"cover", uniform(2, 1, red), "00F", 4, 4, Cover, []color.RGBA{red, red, red, red}},
+		{"downscale", uniform(40, 10, red), "00F", 4, 4, Contain, []color.RGBA{blue, blue, red, blue}},
+		{"transparent", uniform(4, 4, color.Transparent), "00F", 2, 2, Contain, []color.RGBA{blue, blue}},
+		{"default background", uniform(4, 4, color.Transparent), "", 2, 2, Contain, []color.RGBA{white, white}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Fit(tt.src, dvorak.Color(tt.back), tt.w, tt.h, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := got.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+				t.Errorf("got size %dx%d, want %dx%d", b.Dx(), b.Dy(), tt.w, tt.h)
+			}
+			if r := rows(got); !equalColors(r, tt.want) {
+				t.Errorf("got rows %v, want %v", r, tt.want)
+			}
+		})
+	}
+
+	if _, err := Fit(uniform(1, 1, red), "", 0, 10, Contain); err == nil {
+		t.Errorf("Fit with width 0: got nil error")
+	}
+}
+
+func TestFitAverage(t *testing.T) {
+	// A 2x1 image of a red and a blue pixel scaled to half its size
+	// is their average.
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+	got, err := Fit(src, "", 1, 1, Contain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := got.RGBAAt(0, 0); c != (color.RGBA{0x7f, 0, 0x7f, 0xff}) {
+		t.Errorf("got %v, want {7f 0 7f ff}", c)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	pal := image.NewPaletted(image.Rect(0, 0, 3, 3), color.Palette{color.Transparent, red})
+	pal.SetColorIndex(1, 1, 1)
+	var src bytes.Buffer
+	if err := gif.Encode(&src, pal, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := Process(&out, &src, "00F", Options{Width: 6, Height: 6, DPI: 300}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 6 || b.Dy() != 6 {
+		t.Errorf("got size %dx%d, want 6x6", b.Dx(), b.Dy())
+	}
+	if c := color.RGBAModel.Convert(img.At(0, 0)); c != blue {
+		t.Errorf("corner: got %v, want %v", c, blue)
+	}
+	if c := color.RGBAModel.Convert(img.At(3, 3)); c != red {
+		t.Errorf("center: got %v, want %v", c, red)
+	}
+
+	// The pHYs chunk follows IHDR and records 300 dpi in pixels per meter.
+	b := out.Bytes()[pngHeaderLen:]
+	if string(b[4:8]) != "pHYs" {
+		t.Fatalf("chunk after IHDR: got %q, want pHYs", b[4:8])
+	}
+	if ppm := binary.BigEndian.Uint32(b[8:]); ppm != 11811 {
+		t.Errorf("pixels per meter: got %d, want 11811", ppm)
+	}
+}
+
+func TestEncodeNoDPI(t *testing.T) {
+	var out bytes.Buffer
+	if err := Encode(&out, uniform(1, 1, red), 0); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out.Bytes(), []byte("pHYs")) {
+		t.Errorf("Encode with dpi 0 wrote a pHYs chunk")
+	}
+}
+
+func TestSize(t *testing.T) {
+	if got := Size(2.5, 300); got != 750 {
+		t.Errorf("Size(2.5, 300): got %d, want 750", got)
+	}
+}
+
+func equalColors(a, b []color.RGBA) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -9,9 +9,9 @@ import (
 
 // Default header background colors
 const (
-	actionRed = "600"
-	thingBlue = "006"
-	otherGray = "666"
+	actionRed Color = "600"
+	thingBlue Color = "006"
+	otherGray Color = "666"
 )
 
 // Card is a Dvorak card.
@@ -33,10 +33,10 @@ type Card struct {
 	// Type is the card's type, usually "Action" or "Thing".
 	Type []*html.Node
 
-	// BGColor is the color of the card header background,
-	// as a three- or six-digit hex triplet.
-	// If omitted, Parse sets a default value according to the card type.
-	BGColor string
+	// BGColor is the color of the card header background.
+	// If omitted or invalid, Parse sets a default value according to the
+	// card type.
+	BGColor Color
 
 	// CornerValue is an optional value to print in the card's top right corner.
 	CornerValue []*html.Node
@@ -44,9 +44,9 @@ type Card struct {
 	// Image is the filename of an image for the card.
 	Image string
 
-	// ImgBack is the optional color to be shown behind the card image,
-	// as a three- or six-digit hex triplet.
-	ImgBack string
+	// ImgBack is the optional color to be shown behind the card image.
+	// It is empty if omitted or invalid.
+	ImgBack Color
 
 	// FlavorText is the card's flavor text. If not empty, this is displayed
 	// under the rule text, separated by a horizontal line.
@@ -64,6 +64,21 @@ type Card struct {
 	ID int
 }
 
+// cardParams lists the names of the wiki's card template parameters.
+var cardParams = []string{
+	"title", "type", "text", "bgcolor", "cornervalue", "image", "imgback",
+	"flavortext", "creator", "longtitle", "longtext", "minicard",
+}
+
+// newCard returns the Card with the given ID described by params,
+// with a default background color according to rules if none is specified.
+func newCard(params map[string]string, id int, rules ColorRules) Card {
+	c := populateCard(params)
+	c.BGColor = withDefaultColor(c.TypeLine(), c.BGColor, rules)
+	c.ID = id
+	return c
+}
+
 // populateCard returns a Card populated with params.
 func populateCard(params map[string]string) Card {
 	return Card{
@@ -72,10 +87,10 @@ func populateCard(params map[string]string) Card {
 		Text:        parseWikitext(params["text"]),
 		LongText:    params["longtext"] != "",
 		Type:        parseWikitext(params["type"]),
-		BGColor:     params["bgcolor"],
+		BGColor:     colorParam(params["bgcolor"]),
 		CornerValue: parseWikitext(params["cornervalue"]),
 		Image:       params["image"],
-		ImgBack:     params["imgback"],
+		ImgBack:     colorParam(params["imgback"]),
 		FlavorText:  parseWikitext(params["flavortext"]),
 		Creator:     parseWikitext(params["creator"]),
 		MiniCard:    params["minicard"] != "",
@@ -90,6 +105,11 @@ func parseWikitext(s string) []*html.Node {
 	s = replacePair(s, "'''", "<b>", "</b>")
 	s = replacePair(s, "''", "<i>", "</i>")
 
+	return parseHTML(s)
+}
+
+// parseHTML parses s as an HTML fragment in the context of a div element.
+func parseHTML(s string) []*html.Node {
 	r := strings.NewReader(s)
 	frag, err := html.ParseFragmentWithOptions(r,
 		&html.Node{
@@ -108,17 +128,24 @@ func parseWikitext(s string) []*html.Node {
 	return frag
 }
 
-// withDefaultColor returns a default color according to typ if bgcolor is empty.
-func withDefaultColor(typ, bgcolor string) string {
-	if bgcolor == "" {
-		switch strings.ToLower(typ) {
-		case "action":
-			return actionRed
-		case "thing":
-			return thingBlue
-		default:
-			return otherGray
-		}
+// colorParam returns the Color described by s,
+// or the empty string if s is not a valid color.
+func colorParam(s string) Color {
+	c, err := ParseColor(s)
+	if err != nil {
+		return ""
+	}
+	return c
+}
+
+// withDefaultColor returns a default color for typ according to rules
+// if bgcolor is empty. If rules is nil, DefaultColorRules is used.
+func withDefaultColor(typ CardType, bgcolor Color, rules ColorRules) Color {
+	if bgcolor != "" {
+		return bgcolor
+	}
+	if rules == nil {
+		rules = DefaultColorRules
 	}
-	return bgcolor
+	return rules.Color(typ)
 }
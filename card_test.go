@@ -53,9 +53,14 @@ func TestPopulateCard(t *testing.T) {
 		{map[string]string{"bgcolor": "666"}, Card{BGColor: "666"}},
 		{map[string]string{"bgcolor": "000"}, Card{BGColor: "000"}},
 		{map[string]string{"bgcolor": "FFF"}, Card{BGColor: "FFF"}},
+		{map[string]string{"bgcolor": "#ffd700"}, Card{BGColor: "FFD700"}},
+		{map[string]string{"bgcolor": "red"}, Card{BGColor: "F00"}},
+		{map[string]string{"bgcolor": "garbage"}, Card{}},
 		{map[string]string{"cornervalue": "4"}, Card{CornerValue: text("4")}},
 		{map[string]string{"image": "ABC.png"}, Card{Image: "ABC.png"}},
 		{map[string]string{"imgback": "FFD700"}, Card{ImgBack: "FFD700"}},
+		{map[string]string{"imgback": "rgb(0, 0, 0)"}, Card{ImgBack: "000"}},
+		{map[string]string{"imgback": "#12"}, Card{}},
 		{map[string]string{"flavortext": "ABC"}, Card{FlavorText: text("ABC")}},
 		{map[string]string{"creator": "ABC"}, Card{Creator: text("ABC")}},
 		{map[string]string{"minicard": "y"}, Card{MiniCard: true}},
@@ -94,18 +99,24 @@ func TestPopulateCard(t *testing.T) {
 }
 
 func TestWithDefaultColor(t *testing.T) {
-	for _, tt := range []struct{ typ, bgcolor, want string }{
+	for _, tt := range []struct {
+		typ           string
+		bgcolor, want Color
+	}{
 		{"", "", otherGray},
 		{"Special", "", otherGray},
 		{"Action", "", actionRed},
 		{"Thing", "", thingBlue},
-		{"Action - Song", "", otherGray},
-		{"Thing - Moon", "", otherGray},
+		{"action", "", actionRed},
+		{"Action - Song", "", actionRed},
+		{"Thing - Moon", "", thingBlue},
+		{"Action/Thing", "", otherGray},
+		{"Legendary Thing", "", thingBlue},
 		{"Action", thingBlue, thingBlue},
 		{"Thing", actionRed, actionRed},
 		{"Void", "000", "000"},
 	} {
-		got := withDefaultColor(tt.typ, tt.bgcolor)
+		got := withDefaultColor(ParseType(tt.typ), tt.bgcolor, nil)
 		if got != tt.want {
 			t.Errorf("withDefaultColor(%v, %v): got %v, want %v",
 				tt.typ, tt.bgcolor, got, tt.want,
@@ -0,0 +1,174 @@
+package dvorak
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent header that a Client sends by default.
+// MediaWiki asks that clients identify themselves with contact information.
+// https://meta.wikimedia.org/wiki/User-Agent_policy
+const DefaultUserAgent = "dvorak (https://github.com/dkmccandless/dvorak)"
+
+// A Client reads pages from the Dvorak wiki politely: it limits the rate
+// of its requests, retries requests that fail because the wiki is busy,
+// and identifies itself. A Client is safe for concurrent use.
+// The zero value is a Client with no rate limit, retries, or maxlag.
+type Client struct {
+	// Site is the wiki that the Client reads from.
+	// If nil, DefaultSite is used.
+	Site *Site
+
+	// HTTPClient sends the Client's requests.
+	// If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// UserAgent is the User-Agent header of each request.
+	// If empty, DefaultUserAgent is used.
+	UserAgent string
+
+	// Interval is the minimum time between the start of successive requests.
+	Interval time.Duration
+
+	// MaxRetries is the number of times to retry a request whose response
+	// has status 429 Too Many Requests or a 5xx status, or that api.php
+	// rejects because of replication lag.
+	MaxRetries int
+
+	// Backoff is the delay before the first retry of a request whose
+	// response has no Retry-After header. It doubles with each retry.
+	Backoff time.Duration
+
+	// MaxLag is the maxlag parameter of api.php requests in seconds,
+	// which asks the wiki to reject requests when its database replicas
+	// lag by more than MaxLag seconds. If 0, the parameter is omitted.
+	// https://www.mediawiki.org/wiki/Manual:Maxlag_parameter
+	MaxLag int
+
+	mu sync.Mutex
+
+	// next is the earliest time at which to start the next request.
+	next time.Time
+}
+
+// DefaultClient is the Client used by Get and the other package-level
+// functions that read from the wiki.
+var DefaultClient = &Client{
+	Interval:   500 * time.Millisecond,
+	MaxRetries: 4,
+	Backoff:    time.Second,
+	MaxLag:     5,
+}
+
+// wait blocks until c may start another request.
+func (c *Client) wait() {
+	c.mu.Lock()
+	now := time.Now()
+	start := c.next
+	if start.Before(now) {
+		start = now
+	}
+	c.next = start.Add(c.Interval)
+	c.mu.Unlock()
+	time.Sleep(time.Until(start))
+}
+
+// readPage returns the body of the page at url,
+// retrying as configured if the wiki is busy.
+// It returns an error if url cannot be accessed or read from.
+func (c *Client) readPage(url string) ([]byte, error) {
+	backoff := c.Backoff
+	for try := 0; ; try++ {
+		b, retry, err := c.try(url)
+		if retry < 0 || try >= c.MaxRetries {
+			return b, err
+		}
+		if retry == 0 {
+			retry = backoff
+			backoff *= 2
+		}
+		time.Sleep(retry)
+	}
+}
+
+// try sends a single request for url and returns the body of the response.
+// If the request may be retried, it returns the delay requested by
+// the wiki, or 0 if none; otherwise it returns a negative delay.
+func (c *Client) try(url string) (b []byte, retry time.Duration, err error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, -1, err
+	}
+	ua := c.UserAgent
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	c.wait()
+	r, err := hc.Do(req)
+	if err != nil {
+		return nil, -1, err
+	}
+	defer r.Body.Close()
+
+	retry = -1
+	if r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= 500 ||
+		r.Header.Get("MediaWiki-API-Error") == "maxlag" {
+		retry = retryAfter(r.Header.Get("Retry-After"))
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, retry, &HTTPStatusError{URL: url, StatusCode: r.StatusCode}
+	}
+
+	body := r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" && !r.Uncompressed {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, -1, err
+		}
+		defer zr.Close()
+		body = zr
+	}
+	b, err = io.ReadAll(body)
+	if err != nil {
+		return nil, -1, err
+	}
+	return b, retry, nil
+}
+
+// retryAfter returns the delay specified by a Retry-After header value,
+// which is either a number of seconds or an HTTP date,
+// or 0 if it is empty or invalid.
+func retryAfter(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// apiRequestURL returns the URL of an api.php request with params.
+func (c *Client) apiRequestURL(params url.Values) string {
+	if c.MaxLag > 0 {
+		params.Set("maxlag", strconv.Itoa(c.MaxLag))
+	}
+	return c.site().APIURL() + "?" + params.Encode()
+}
@@ -0,0 +1,144 @@
+package dvorak
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClientRetry(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		want       string
+		ok         bool
+		requests   int
+	}{
+		{"ok", []int{200}, 2, "body 1", true, 1},
+		{"503", []int{503, 200}, 2, "body 2", true, 2},
+		{"429", []int{429, 429, 200}, 2, "body 3", true, 3},
+		{"exhausted", []int{503, 503, 503}, 2, "", false, 3},
+		{"404", []int{404, 200}, 2, "", false, 1},
+		{"no retries", []int{500, 200}, 0, "", false, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var n int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[n]
+				n++
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(status)
+				w.Write([]byte("body " + string(rune('0'+n))))
+			}))
+			defer ts.Close()
+
+			c := &Client{MaxRetries: tt.maxRetries, Backoff: time.Millisecond}
+			b, err := c.readPage(ts.URL)
+			if string(b) != tt.want || (err == nil) != tt.ok {
+				t.Errorf("readPage: got %q, %v; want %q, ok %v", b, err, tt.want, tt.ok)
+			}
+			if n != tt.requests {
+				t.Errorf("got %d requests, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestClientMaxLag(t *testing.T) {
+	var queries []url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		if len(queries) == 1 {
+			w.Header().Set("MediaWiki-API-Error", "maxlag")
+			w.Header().Set("Retry-After", "0")
+			w.Write([]byte(`{"error": {"code": "maxlag", "info": "Waiting for a database server: 6 seconds lagged."}}`))
+			return
+		}
+		w.Write([]byte(`{"query": {}}`))
+	}))
+	defer ts.Close()
+	c := &Client{Site: &Site{URL: ts.URL}, MaxRetries: 1, MaxLag: 5}
+	var v struct{}
+	if err := c.queryAPI(url.Values{"action": {"query"}}, &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("got %d requests, want 2", len(queries))
+	}
+	if got := queries[0].Get("maxlag"); got != "5" {
+		t.Errorf("maxlag: got %q, want %q", got, "5")
+	}
+}
+
+func TestClientHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "test agent" {
+			t.Errorf("User-Agent: got %q, want %q", ua, "test agent")
+		}
+		if ae := r.Header.Get("Accept-Encoding"); ae != "gzip" {
+			t.Errorf("Accept-Encoding: got %q, want %q", ae, "gzip")
+		}
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		zw.Write([]byte("{{card|title=A}}"))
+		zw.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(b.Bytes())
+	}))
+	defer ts.Close()
+
+	c := &Client{UserAgent: "test agent"}
+	b, err := c.readPage(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{{card|title=A}}"; string(b) != want {
+		t.Errorf("readPage: got %q, want %q", b, want)
+	}
+}
+
+func TestClientInterval(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	const interval = 20 * time.Millisecond
+	c := &Client{Interval: interval}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := c.readPage(ts.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(start); d < 2*interval {
+		t.Errorf("3 requests took %v, want at least %v", d, 2*interval)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want time.Duration
+	}{
+		{"", 0},
+		{"0", 0},
+		{"3", 3 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0},
+	} {
+		if got := retryAfter(tt.s); got != tt.want {
+			t.Errorf("retryAfter(%q): got %v, want %v", tt.s, got, tt.want)
+		}
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := retryAfter(future); got < 59*time.Minute || got > time.Hour {
+		t.Errorf("retryAfter(%q): got %v, want about 1h", future, got)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dkmccandless/dvorak"
+	"github.com/dkmccandless/dvorak/art"
+)
+
+func runArt(args []string) error {
+	fs := newFlagSet("art")
+	var o options
+	o.register(fs)
+	dir := fs.String("o", "art", "write the art of each card to `dir` as ID.png")
+	store := fs.String("store", "images", "download the images into the image store in `dir`")
+	width := fs.Int("width", 750, "art width in pixels")
+	height := fs.Int("height", 540, "art height in pixels")
+	dpi := fs.Int("dpi", 300, "art resolution in dots per inch")
+	cover := fs.Bool("cover", false, "crop the images to cover the art instead of fitting within it")
+	fs.Parse(args)
+	o.configure()
+
+	d, err := o.load(deckArg(fs))
+	if err != nil {
+		return err
+	}
+	s := &dvorak.ImageStore{Dir: *store}
+	paths, err := dvorak.FetchImages(d.Cards, s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+	opts := art.Options{Width: *width, Height: *height, DPI: *dpi}
+	if *cover {
+		opts.Mode = art.Cover
+	}
+	for _, c := range d.Cards {
+		p, ok := paths[c.Image]
+		if !ok {
+			if c.Image != "" {
+				fmt.Fprintf(os.Stderr, "card %d: image %q not found\n", c.ID, c.Image)
+			}
+			continue
+		}
+		if err := processArt(filepath.Join(*dir, fmt.Sprintf("%d.png", c.ID)), p, c.ImgBack, opts); err != nil {
+			return fmt.Errorf("card %d: %v", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// processArt writes the art made from the image file src to the named file.
+func processArt(name, src string, back dvorak.Color, opts art.Options) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := art.Process(out, in, back, opts); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func runDecks(args []string) error {
+	fs := flag.NewFlagSet("decks", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dvorak decks [flags]")
+		fs.PrintDefaults()
+	}
+	var o options
+	o.registerClient(fs)
+	asJSON := fs.Bool("json", false, "print decks as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	o.configure()
+	decks, err := dvorak.ListDecks()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		return enc.Encode(decks)
+	}
+	for _, d := range decks {
+		fmt.Printf("%s\t%d\t%s\t%s\n", d.Title, d.Cards, d.Modified.Format(time.RFC3339), d.URL)
+	}
+	return nil
+}
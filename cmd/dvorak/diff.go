@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func runDiff(args []string) error {
+	fs := newFlagSet("diff")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dvorak diff [flags] <old deck> <new deck>")
+		fs.PrintDefaults()
+	}
+	var o options
+	o.register(fs)
+	format := fs.String("format", "text", "output `format`: text, json, or html")
+	out := fs.String("o", "", "write to `file` instead of standard output")
+	fs.Parse(args)
+	o.configure()
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var write func(d dvorak.DeckDiff, w io.Writer) error
+	switch *format {
+	case "text":
+		write = dvorak.DeckDiff.WriteText
+	case "json":
+		write = dvorak.DeckDiff.WriteJSON
+	case "html":
+		write = dvorak.DeckDiff.WriteHTML
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	from, err := o.load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	to, err := o.load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	d := dvorak.Diff(from.Cards, to.Cards)
+	return writeOutput(*out, func(w io.Writer) error { return write(d, w) })
+}
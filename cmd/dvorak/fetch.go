@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func runFetch(args []string) error {
+	fs := newFlagSet("fetch")
+	var o options
+	o.register(fs)
+	asJSON := fs.Bool("json", false, "print the deck's pages and their revisions as JSON, following redirects")
+	fs.Parse(args)
+	o.configure()
+	deck := deckArg(fs)
+
+	if *asJSON {
+		if !isURL(deck) {
+			return fmt.Errorf("-json requires a deck URL")
+		}
+		d, err := dvorak.Fetch(deck)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		return enc.Encode(d)
+	}
+
+	b, err := o.source(deck)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(b)
+	return err
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func runHistory(args []string) error {
+	fs := newFlagSet("history")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dvorak history [flags] <deck URL>")
+		fs.PrintDefaults()
+	}
+	var o options
+	o.registerClient(fs)
+	asJSON := fs.Bool("json", false, "print revisions as JSON")
+	fs.Parse(args)
+
+	deck := deckArg(fs)
+	if !isURL(deck) {
+		return fmt.Errorf("%s: not a wiki URL", deck)
+	}
+	o.configure()
+	h, err := dvorak.History(deck)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		return enc.Encode(h)
+	}
+	if h.RedirectedFrom != "" {
+		fmt.Fprintf(os.Stderr, "%s redirects to %s\n", h.RedirectedFrom, h.Title)
+	}
+	for _, r := range h.Revisions {
+		fmt.Printf("%d\t%s\t%s\t%s\n", r.ID, r.Timestamp.Format(time.RFC3339), r.User, r.Comment)
+	}
+	return nil
+}
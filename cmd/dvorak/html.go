@@ -0,0 +1,186 @@
+package main
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/dkmccandless/dvorak"
+	"golang.org/x/net/html"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"rich": rich,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// artWidth and artHeight are the size in pixels of the card image
+// thumbnails to request: twice the size of a card's art box,
+// for high-density displays.
+const (
+	artWidth  = 500
+	artHeight = 360
+)
+
+// deckPage is the data of the deck template.
+type deckPage struct {
+	Name  string
+	Cards []cardView
+
+	// Credits lists the attribution of the cards' images, if requested.
+	Credits []creditView
+}
+
+// creditView is the data of a row of the credits page.
+type creditView struct {
+	Card dvorak.Card
+
+	// Info describes the card's image. Info.Credit is not nil.
+	Info dvorak.ImageInfo
+}
+
+// cardView is the data of the card template.
+type cardView struct {
+	dvorak.Card
+
+	// ImageURL is the URL of the card's image, if known.
+	ImageURL string
+
+	// Link is the URL of the card's permalink, if any.
+	Link string
+}
+
+// newDeckPage returns the template data of d.
+// images maps Card.Image values to URLs, and credits maps them to
+// information including their Credit.
+func newDeckPage(d dvorak.Deck, images map[string]string, credits map[string]dvorak.ImageInfo) deckPage {
+	p := deckPage{Name: d.Name, Credits: creditViews(d.Cards, credits)}
+	for _, c := range d.Cards {
+		p.Cards = append(p.Cards, cardView{Card: c, ImageURL: images[c.Image]})
+	}
+	return p
+}
+
+// creditViews returns the credits of the images of cards, in order.
+// credits maps Card.Image values to information including their Credit.
+func creditViews(cards []dvorak.Card, credits map[string]dvorak.ImageInfo) []creditView {
+	var cv []creditView
+	for _, c := range cards {
+		if info, ok := credits[c.Image]; ok && info.Credit != nil {
+			cv = append(cv, creditView{Card: c, Info: info})
+		}
+	}
+	return cv
+}
+
+// writeHTML writes d to w as an HTML page.
+// images maps Card.Image values to URLs, and credits, if not nil,
+// maps them to the information listed on the page's credits.
+func writeHTML(w io.Writer, d dvorak.Deck, images map[string]string, credits map[string]dvorak.ImageInfo) error {
+	return templates.ExecuteTemplate(w, "deck.html", newDeckPage(d, images, credits))
+}
+
+// rich renders frag as HTML, removing elements and attributes
+// that the wiki would not allow.
+func rich(frag []*html.Node) template.HTML {
+	var b strings.Builder
+	for _, n := range frag {
+		writeSanitized(&b, n)
+	}
+	return template.HTML(b.String())
+}
+
+// allowedElements is the set of HTML elements that rich renders.
+// Other elements are replaced by their content.
+var allowedElements = map[string]bool{
+	"b": true, "big": true, "blockquote": true, "br": true, "center": true,
+	"code": true, "dd": true, "del": true, "div": true, "dl": true,
+	"dt": true, "em": true, "font": true, "hr": true, "i": true,
+	"ins": true, "li": true, "ol": true, "p": true, "pre": true,
+	"s": true, "small": true, "span": true, "strike": true, "strong": true,
+	"sub": true, "sup": true, "table": true, "tbody": true, "td": true,
+	"th": true, "thead": true, "tr": true, "tt": true, "u": true,
+	"ul": true,
+}
+
+// allowedAttrs is the set of HTML attributes that rich renders.
+var allowedAttrs = map[string]bool{
+	"align": true, "class": true, "color": true, "face": true,
+	"size": true, "style": true, "title": true,
+}
+
+// allowedStyles is the set of CSS properties that rich renders
+// in style attributes.
+var allowedStyles = map[string]bool{
+	"background-color": true, "color": true, "font-family": true,
+	"font-size": true, "font-style": true, "font-variant": true,
+	"font-weight": true, "text-align": true, "text-decoration": true,
+	"text-transform": true, "vertical-align": true,
+}
+
+// styleValue matches the CSS property values that rich renders:
+// keywords, numbers, lengths, percentages, hex colors,
+// and rgb() and rgba() colors. It excludes escapes, comments, strings,
+// and other functions, such as url().
+var styleValue = regexp.MustCompile(`^(?:[a-z0-9#%.,+\- ]|rgba?\([0-9.,%/ ]*\))+$`)
+
+// sanitizeStyle returns the declarations of the CSS style attribute
+// value s whose properties are in allowedStyles and whose values
+// match styleValue, or the empty string if there are none.
+func sanitizeStyle(s string) string {
+	var decls []string
+	for _, d := range strings.Split(s, ";") {
+		i := strings.Index(d, ":")
+		if i < 0 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(d[:i]))
+		val := strings.ToLower(strings.Join(strings.Fields(d[i+1:]), " "))
+		if allowedStyles[prop] && styleValue.MatchString(val) {
+			decls = append(decls, prop+": "+val)
+		}
+	}
+	return strings.Join(decls, "; ")
+}
+
+// writeSanitized writes n and its allowed descendants to b.
+func writeSanitized(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if n.Data == "script" || n.Data == "style" {
+		return
+	}
+	allowed := allowedElements[n.Data]
+	if allowed {
+		b.WriteString("<" + n.Data)
+		for _, a := range n.Attr {
+			v := a.Val
+			if a.Key == "style" {
+				v = sanitizeStyle(v)
+			}
+			if allowedAttrs[a.Key] && v != "" {
+				b.WriteString(" " + a.Key + `="` + html.EscapeString(v) + `"`)
+			}
+		}
+		b.WriteString(">")
+		if n.Data == "br" || n.Data == "hr" {
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeSanitized(b, c)
+	}
+	if allowed {
+		b.WriteString("</" + n.Data + ">")
+	}
+}
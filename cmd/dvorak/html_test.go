@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func TestRich(t *testing.T) {
+	for _, tt := range []struct{ s, want string }{
+		{"{{card|text=Draw a card.}}", "Draw a card."},
+		{"{{card|text='''Action:''' Draw.<br>Discard.}}", "<b>Action:</b> Draw.<br>Discard."},
+		{"{{card|text=<font color=FFD700>Gold</font>}}", `<font color="FFD700">Gold</font>`},
+		{"{{card|text=Draw<script>alert(1)</script>}}", "Draw"},
+		{"{{card|text=<span onclick=alert(1) style=color:red>Red</span>}}", `<span style="color: red">Red</span>`},
+		{"{{card|text=<span style=background:url(x)>X</span>}}", "<span>X</span>"},
+		{`{{card|text=<span style="color:red; background-image:\75rl(x)">X</span>}}`, `<span style="color: red">X</span>`},
+		{`{{card|text=<span style="color:e\78pression(alert(1))">X</span>}}`, "<span>X</span>"},
+		{`{{card|text=<span style="COLOR: rgb(255, 0, 0);font-weight:bold;/*x*/">X</span>}}`, `<span style="color: rgb(255, 0, 0); font-weight: bold">X</span>`},
+		{`{{card|text=<span style="font-family:'a;b'">X</span>}}`, "<span>X</span>"},
+		{"{{card|text=<a href=javascript:alert(1)>Link</a>}}", "Link"},
+		{"{{card|text=Replace <metal> & stuff}}", "Replace &lt;metal&gt; &amp; stuff"},
+	} {
+		c := dvorak.Parse([]byte(tt.s))[0]
+		if got := string(rich(c.Text)); got != tt.want {
+			t.Errorf("rich(%q): got %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	d := dvorak.Deck{
+		Name:  "Test <Deck>",
+		Cards: dvorak.Parse([]byte("{{card|title=A|type=Thing|image=A.png|imgback=FFD700}}")),
+	}
+	var b strings.Builder
+	if err := writeHTML(&b, d, map[string]string{"A.png": "https://dvorakgame.co.uk/images/A.png"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>Test &lt;Deck&gt;</title>",
+		`style="background-color: #006; color: #FFF"`,
+		`<div class="art" style="background-color: #FFD700"><img src="https://dvorakgame.co.uk/images/A.png" alt="A.png"></div>`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("writeHTML: output does not contain %q:\n%v", want, b.String())
+		}
+	}
+}
+
+func TestWriteHTMLCredits(t *testing.T) {
+	d := dvorak.Deck{
+		Name:  "Test",
+		Cards: dvorak.Parse([]byte("{{card|title=''A''|image=A.png}}{{card|title=B}}")),
+	}
+	credits := map[string]dvorak.ImageInfo{
+		"A.png": {
+			Name: "A.png",
+			Credit: &dvorak.ImageCredit{
+				Author:     "Alice <3",
+				License:    "CC BY-SA 4.0",
+				LicenseURL: "https://creativecommons.org/licenses/by-sa/4.0",
+				Uploader:   "Binarius",
+			},
+		},
+	}
+	var b strings.Builder
+	if err := writeHTML(&b, d, nil, credits); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<h2>Image credits</h2>",
+		"<td>1. <i>A</i></td>",
+		"<td>Alice &lt;3</td>",
+		`<a href="https://creativecommons.org/licenses/by-sa/4.0">CC BY-SA 4.0</a>`,
+		"<td>Binarius</td>",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("writeHTML: output does not contain %q:\n%v", want, b.String())
+		}
+	}
+
+	b.Reset()
+	if err := writeHTML(&b, d, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "Image credits") {
+		t.Errorf("writeHTML without credits: output contains credits:\n%v", b.String())
+	}
+}
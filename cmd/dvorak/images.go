@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func runImages(args []string) error {
+	fs := newFlagSet("images")
+	var o options
+	o.register(fs)
+	dir := fs.String("o", "", "download the images into `dir` instead of listing their URLs")
+	store := fs.String("store", "", "download the images into the image store in `dir` and list their files")
+	fs.Parse(args)
+	o.configure()
+
+	d, err := o.load(deckArg(fs))
+	if err != nil {
+		return err
+	}
+	if *store != "" {
+		return storeImages(d.Cards, &dvorak.ImageStore{Dir: *store})
+	}
+	infos, err := dvorak.ImageURLs(d.Cards)
+	if err != nil {
+		return err
+	}
+	if *dir != "" {
+		if err := os.MkdirAll(*dir, 0o755); err != nil {
+			return err
+		}
+	}
+	for _, c := range d.Cards {
+		if c.Image == "" {
+			continue
+		}
+		info := infos[c.Image]
+		switch {
+		case info.Invalid:
+			fmt.Fprintf(os.Stderr, "card %d: invalid image name %q\n", c.ID, c.Image)
+			continue
+		case info.URL == "":
+			fmt.Fprintf(os.Stderr, "card %d: image %q not found\n", c.ID, c.Image)
+			continue
+		}
+		if *dir == "" {
+			fmt.Printf("%s\t%s\n", c.Image, info.URL)
+			continue
+		}
+		b, err := o.download(info.URL)
+		if err != nil {
+			return err
+		}
+		name := filepath.Join(*dir, filepath.Base(info.Name))
+		if err := os.WriteFile(name, b, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeImages downloads the images of cards into s
+// and lists the stored file of each.
+func storeImages(cards []dvorak.Card, s *dvorak.ImageStore) error {
+	paths, err := dvorak.FetchImages(cards, s)
+	for _, c := range cards {
+		if p, ok := paths[c.Image]; ok {
+			fmt.Printf("%s\t%s\n", c.Image, p)
+		}
+	}
+	return err
+}
+
+// imageURLs returns the URLs of the images of cards that exist
+// on the wiki, keyed by Card.Image. If width or height is not 0,
+// the URLs are of thumbnails that fit within that size.
+func imageURLs(cards []dvorak.Card, width, height int) (map[string]string, error) {
+	infos, err := dvorak.Thumbnails(cards, width, height)
+	if err != nil {
+		return nil, err
+	}
+	urls := make(map[string]string)
+	for name, info := range infos {
+		switch {
+		case info.ThumbURL != "":
+			urls[name] = info.ThumbURL
+		case info.URL != "":
+			urls[name] = info.URL
+		}
+	}
+	return urls, nil
+}
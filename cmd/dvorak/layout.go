@@ -0,0 +1,184 @@
+package main
+
+import (
+	"image"
+	"strings"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+// A canvas is a surface on which drawCard draws a card for printing.
+// Coordinates and sizes are in points (1/72 inch), measured from the
+// top left corner of the card.
+type canvas interface {
+	// fill fills a rectangle with color c.
+	fill(x, y, w, h float64, c dvorak.Color)
+
+	// image draws img fitted within a rectangle over the color back.
+	image(x, y, w, h float64, img image.Image, back dvorak.Color)
+
+	// text draws a line of text with its top left corner at (x, y) in a
+	// monospace font of the given size. s contains only printable ASCII
+	// characters.
+	text(x, y, size float64, bold bool, c dvorak.Color, s string)
+
+	// charWidth returns the width of a character of text of the given size.
+	charWidth(size float64) float64
+}
+
+// The size of a printed card and its parts, in points. The art box is
+// the size of the art command's default output at its default resolution.
+const (
+	cardWidth    = 180 // 2.5 in
+	cardHeight   = 252 // 3.5 in
+	cardMargin   = 6
+	headerHeight = 24
+	typeHeight   = 14
+	artTop       = headerHeight + typeHeight
+	artBoxHeight = 129.6 // 1.8 in
+	ruleWidth    = 0.75
+)
+
+// Font sizes in points, and the height of a line of text
+// as a multiple of its font size.
+const (
+	titleSize     = 10
+	longTitleSize = 8
+	bodySize      = 7
+	longBodySize  = 6
+	creatorSize   = 6
+	lineHeight    = 1.2
+)
+
+// drawCard draws c on cv with the art img, which may be nil.
+func drawCard(cv canvas, c dvorak.Card, img image.Image) {
+	cv.fill(0, 0, cardWidth, cardHeight, "FFF")
+	// The border is drawn last, over the header and art.
+	defer func() {
+		cv.fill(0, 0, cardWidth, ruleWidth, "000")
+		cv.fill(0, cardHeight-ruleWidth, cardWidth, ruleWidth, "000")
+		cv.fill(0, 0, ruleWidth, cardHeight, "000")
+		cv.fill(cardWidth-ruleWidth, 0, ruleWidth, cardHeight, "000")
+	}()
+
+	cv.fill(0, 0, cardWidth, headerHeight, c.BGColor)
+	size := float64(titleSize)
+	if c.LongTitle {
+		size = longTitleSize
+	}
+	cols := columns(cv, size, cardWidth-2*cardMargin)
+	corner := asciiText(plainLine(dvorak.Card{Text: c.CornerValue}.PlainText()))
+	if corner != "" {
+		corner = truncate(corner, cols/3)
+		x := cardWidth - cardMargin - float64(len(corner))*cv.charWidth(size)
+		cv.text(x, (headerHeight-size)/2, size, true, c.BGColor.Contrast(), corner)
+		cols -= len(corner) + 1
+	}
+	title := truncate(asciiText(plainLine(c.TitleText())), cols)
+	cv.text(cardMargin, (headerHeight-size)/2, size, true, c.BGColor.Contrast(), title)
+
+	typ := truncate(asciiText(c.TypeText()), columns(cv, bodySize, cardWidth-2*cardMargin))
+	cv.text(cardMargin, headerHeight+(typeHeight-bodySize)/2, bodySize, false, "000", typ)
+	cv.fill(0, artTop-ruleWidth, cardWidth, ruleWidth, "000")
+
+	switch {
+	case img != nil:
+		cv.image(0, artTop, cardWidth, artBoxHeight, img, c.ImgBack)
+	case c.ImgBack != "":
+		cv.fill(0, artTop, cardWidth, artBoxHeight, c.ImgBack)
+	}
+	cv.fill(0, artTop+artBoxHeight, cardWidth, ruleWidth, "000")
+
+	bottom := float64(cardHeight - cardMargin)
+	if creator := asciiText(plainLine(c.CreatorText())); creator != "" {
+		creator = truncate(creator, columns(cv, creatorSize, cardWidth-2*cardMargin))
+		x := cardWidth - cardMargin - float64(len(creator))*cv.charWidth(creatorSize)
+		bottom -= creatorSize
+		cv.text(x, bottom, creatorSize, false, "000", creator)
+		bottom -= creatorSize / 2
+	}
+
+	size = bodySize
+	if c.LongText {
+		size = longBodySize
+	}
+	cols = columns(cv, size, cardWidth-2*cardMargin)
+	y := artTop + artBoxHeight + cardMargin
+	for _, line := range wrapText(asciiText(c.PlainText()), cols) {
+		if y+size > bottom {
+			return
+		}
+		cv.text(cardMargin, y, size, false, "000", line)
+		y += size * lineHeight
+	}
+	if flavor := asciiText(c.FlavorPlainText()); flavor != "" && y+size < bottom {
+		cv.fill(cardMargin, y+size*(lineHeight-1), cardWidth-2*cardMargin, ruleWidth, "000")
+		y += size * lineHeight / 2
+		for _, line := range wrapText(flavor, cols) {
+			if y+size > bottom {
+				return
+			}
+			cv.text(cardMargin, y, size, false, "000", line)
+			y += size * lineHeight
+		}
+	}
+}
+
+// columns returns the number of characters of text of the given size
+// that fit in width on cv.
+func columns(cv canvas, size, width float64) int {
+	return int(width / cv.charWidth(size))
+}
+
+// plainLine returns s on a single line.
+func plainLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate returns s shortened to at most n characters,
+// ending with "..." if it was shortened.
+func truncate(s string, n int) string {
+	switch {
+	case len(s) <= n:
+		return s
+	case n <= 3:
+		return ""
+	}
+	return s[:n-3] + "..."
+}
+
+// wrapText breaks the lines of s into lines of at most cols characters
+// at spaces, or within words that are longer than cols.
+func wrapText(s string, cols int) []string {
+	if cols < 1 {
+		return nil
+	}
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		var line string
+		for _, word := range strings.Fields(para) {
+			switch {
+			case line == "":
+			case len(line)+1+len(word) <= cols:
+				line += " " + word
+				continue
+			default:
+				lines = append(lines, line)
+			}
+			for len(word) > cols {
+				lines = append(lines, word[:cols])
+				word = word[cols:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	// Remove trailing blank lines.
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return lines
+}
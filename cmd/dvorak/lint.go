@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dkmccandless/dvorak"
+	"github.com/dkmccandless/dvorak/lint"
+)
+
+func runLint(args []string) error {
+	fs := newFlagSet("lint")
+	var o options
+	o.register(fs)
+	config := fs.String("config", "", "read per-deck rule settings from JSON `file`")
+	asJSON := fs.Bool("json", false, "print findings as JSON")
+	images := fs.Bool("images", false, "check that card images can be found on the wiki")
+	fs.Parse(args)
+	o.configure()
+
+	deck := deckArg(fs)
+	settings := new(lint.Settings)
+	if *config != "" {
+		f, err := os.Open(*config)
+		if err != nil {
+			return err
+		}
+		settings, err = lint.ReadSettings(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", *config, err)
+		}
+	}
+	b, err := o.source(deck)
+	if err != nil {
+		return err
+	}
+	cfg := settings.For(deckName(deck))
+	if *images {
+		urls, err := imageURLs(dvorak.Parse(b), 0, 0)
+		if err != nil {
+			return err
+		}
+		cfg.Images = make(map[string]bool)
+		for name := range urls {
+			cfg.Images[name] = true
+		}
+	}
+
+	findings := lint.Lint(b, cfg)
+	if *asJSON {
+		b, err := lint.JSON(findings)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n", b)
+	} else {
+		for _, f := range findings {
+			fmt.Printf("%s:%v\n", deck, f)
+		}
+	}
+	for _, f := range findings {
+		if f.Severity > lint.Info {
+			return errSilent
+		}
+	}
+	return nil
+}
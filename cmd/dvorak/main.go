@@ -0,0 +1,107 @@
+// Command dvorak fetches, parses, and renders decks from the Dvorak game wiki.
+//
+// Usage:
+//
+//	dvorak <command> [flags] <deck>
+//
+// The diff command takes two decks, the search and serve commands
+// take any number of decks, and the decks command takes none.
+// The deck is the URL of a deck page on the wiki or the name of a local file
+// containing its source code. The commands are:
+//
+//	fetch   print the source code of a deck, beginning with its subpages
+//	parse   print the cards of a deck as text, JSON, CSV, or TSV
+//	images  list or download the images of a deck's cards
+//	art     fit the images of a deck's cards to their art boxes for printing
+//	render  write the cards of a deck as an HTML page, a PDF, or PNG images
+//	lint    report problems with a deck's card templates
+//	stats   print statistics of a deck as text, JSON, or HTML
+//	diff    compare the cards of two versions of a deck
+//	history list the revisions of a deck page
+//	decks   list the decks on the wiki
+//	search  search the cards of decks
+//	serve   serve a browsable gallery of the cards of decks
+//
+// Run "dvorak <command> -h" for the flags of each command.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// A command is a subcommand of dvorak.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"fetch", "print the source code of a deck, beginning with its subpages", runFetch},
+	{"parse", "print the cards of a deck as text, JSON, CSV, or TSV", runParse},
+	{"images", "list or download the images of a deck's cards", runImages},
+	{"art", "fit the images of a deck's cards to their art boxes for printing", runArt},
+	{"render", "write the cards of a deck as an HTML page, a PDF, or PNG images", runRender},
+	{"lint", "report problems with a deck's card templates", runLint},
+	{"stats", "print statistics of a deck as text, JSON, or HTML", runStats},
+	{"diff", "compare the cards of two versions of a deck", runDiff},
+	{"history", "list the revisions of a deck page", runHistory},
+	{"decks", "list the decks on the wiki", runDecks},
+	{"search", "search the cards of decks", runSearch},
+	{"serve", "serve a browsable gallery of the cards of decks", runServe},
+}
+
+// errSilent is returned by commands that have already reported their failure.
+var errSilent = errors.New("silent error")
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		if err := c.run(os.Args[2:]); err != nil {
+			if err != errSilent {
+				fmt.Fprintf(os.Stderr, "dvorak %s: %v\n", c.name, err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "dvorak: unknown command %q\n", os.Args[1])
+	usage()
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dvorak <command> [flags] <deck>")
+	fmt.Fprintln(os.Stderr, "\nThe commands are:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-8s%s\n", c.name, c.summary)
+	}
+	os.Exit(2)
+}
+
+// newFlagSet returns a FlagSet for the named command
+// that takes a single deck argument.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: dvorak %s [flags] <deck>\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// deckArg returns the single positional argument of fs.
+func deckArg(fs *flag.FlagSet) string {
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return fs.Arg(0)
+}
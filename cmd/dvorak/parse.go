@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func runParse(args []string) error {
+	fs := newFlagSet("parse")
+	var o options
+	o.register(fs)
+	format := fs.String("format", "text", "output `format`: text, json, csv, or tsv")
+	fs.Parse(args)
+	o.configure()
+
+	d, err := o.load(deckArg(fs))
+	if err != nil {
+		return err
+	}
+	return writeDeck(os.Stdout, d, *format)
+}
+
+// writeDeck writes the cards of d to w in the named format.
+func writeDeck(w io.Writer, d dvorak.Deck, format string) error {
+	switch format {
+	case "text":
+		return writeText(w, d.Cards)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(d)
+	case "csv":
+		return dvorak.WriteCSV(w, d.Cards)
+	case "tsv":
+		return dvorak.WriteTSV(w, d.Cards)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// writeText writes a plain text description of each card to w.
+func writeText(w io.Writer, cards []dvorak.Card) error {
+	for i, c := range cards {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "#%d %s", c.ID, c.TitleText())
+		if t := c.TypeText(); t != "" {
+			fmt.Fprintf(w, " (%s)", t)
+		}
+		fmt.Fprintln(w)
+		if t := c.PlainText(); t != "" {
+			fmt.Fprintln(w, t)
+		}
+		if t := c.FlavorPlainText(); t != "" {
+			fmt.Fprintf(w, "-- %s\n", t)
+		}
+		if t := c.CreatorText(); t != "" {
+			fmt.Fprintf(w, "Created by %s\n", t)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dkmccandless/dvorak"
+	"github.com/dkmccandless/dvorak/art"
+)
+
+// The size of an A4 page in points, and the number of cards on each page.
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	pageCols   = 3
+	pageRows   = 3
+)
+
+// A pdfDoc is a PDF document under construction. It holds the objects
+// of the document, which are numbered from 1, and writes them with their
+// cross-reference table.
+// https://opensource.adobe.com/dc-acrobat-sdk-docs/pdfstandards/PDF32000_2008.pdf
+type pdfDoc struct {
+	objs   [][]byte
+	pages  []int
+	images []int
+}
+
+// The objects that every pdfDoc begins with.
+const (
+	catalogObj = iota + 1
+	pagesObj
+	resourcesObj
+	fontObj
+	boldFontObj
+)
+
+// newPDFDoc returns a pdfDoc with its fonts.
+// Its catalog, pages, and resources objects are written by writeTo.
+func newPDFDoc() *pdfDoc {
+	d := &pdfDoc{objs: make([][]byte, boldFontObj)}
+	d.objs[fontObj-1] = []byte("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	d.objs[boldFontObj-1] = []byte("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	return d
+}
+
+// add adds the object b to d and returns its number.
+func (d *pdfDoc) add(b []byte) int {
+	d.objs = append(d.objs, b)
+	return len(d.objs)
+}
+
+// addStream adds a stream object containing b, compressed,
+// with the entries dict in its dictionary, and returns its number.
+func (d *pdfDoc) addStream(dict string, b []byte) int {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(b)
+	zw.Close()
+	if dict != "" {
+		dict += " "
+	}
+	var obj bytes.Buffer
+	fmt.Fprintf(&obj, "<< %s/Filter /FlateDecode /Length %d >>\nstream\n", dict, z.Len())
+	obj.Write(z.Bytes())
+	obj.WriteString("\nendstream")
+	return d.add(obj.Bytes())
+}
+
+// addPage adds a page whose content stream is content.
+func (d *pdfDoc) addPage(content []byte) {
+	c := d.addStream("", content)
+	d.pages = append(d.pages, d.add([]byte(fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %d 0 R /Contents %d 0 R >>",
+		pagesObj, pdfNum(pageWidth), pdfNum(pageHeight), resourcesObj, c,
+	))))
+}
+
+// addImage adds img as an image XObject and returns its resource name.
+func (d *pdfDoc) addImage(img image.Image) string {
+	b := img.Bounds()
+	pix := make([]byte, 0, 3*b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			pix = append(pix, byte(r>>8), byte(g>>8), byte(bl>>8))
+		}
+	}
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8", b.Dx(), b.Dy())
+	d.images = append(d.images, d.addStream(dict, pix))
+	return fmt.Sprintf("Im%d", len(d.images))
+}
+
+// writeTo writes d to w.
+func (d *pdfDoc) writeTo(w io.Writer) error {
+	var kids, images strings.Builder
+	for _, p := range d.pages {
+		fmt.Fprintf(&kids, " %d 0 R", p)
+	}
+	for i, obj := range d.images {
+		fmt.Fprintf(&images, " /Im%d %d 0 R", i+1, obj)
+	}
+	d.objs[catalogObj-1] = []byte(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+	d.objs[pagesObj-1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s ] /Count %d >>", kids.String(), len(d.pages)))
+	d.objs[resourcesObj-1] = []byte(fmt.Sprintf(
+		"<< /Font << /F1 %d 0 R /F2 %d 0 R >> /XObject <<%s >> >>",
+		fontObj, boldFontObj, images.String(),
+	))
+
+	cw := &countWriter{w: bufio.NewWriter(w)}
+	// The comment of high bytes marks the file as binary.
+	fmt.Fprint(cw, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int64, len(d.objs))
+	for i, obj := range d.objs {
+		offsets[i] = cw.n
+		fmt.Fprintf(cw, "%d 0 obj\n", i+1)
+		cw.Write(obj)
+		fmt.Fprint(cw, "\nendobj\n")
+	}
+	xref := cw.n
+	fmt.Fprintf(cw, "xref\n0 %d\n0000000000 65535 f \n", len(d.objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(cw, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(cw, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objs)+1, catalogObj, xref)
+	if cw.err != nil {
+		return cw.err
+	}
+	return cw.w.Flush()
+}
+
+// A countWriter counts the bytes written to w
+// and records the first error.
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) Write(b []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+// pdfCanvas is a canvas that draws a card on a page of a pdfDoc by
+// appending operators to the page's content stream. Images are embedded
+// at a resolution of dpi dots per inch.
+type pdfCanvas struct {
+	doc     *pdfDoc
+	content *bytes.Buffer
+	dpi     int
+
+	// x and y are the position on the page of the card's
+	// top left corner, from the bottom left corner of the page.
+	x, y float64
+}
+
+// rect returns the PDF coordinates of the lower left corner of a
+// rectangle with the given position and size on the card.
+func (cv *pdfCanvas) rect(x, y, h float64) (float64, float64) {
+	return cv.x + x, cv.y - y - h
+}
+
+func (cv *pdfCanvas) fill(x, y, w, h float64, c dvorak.Color) {
+	px, py := cv.rect(x, y, h)
+	fmt.Fprintf(cv.content, "%s rg %s %s %s %s re f\n", pdfColor(c), pdfNum(px), pdfNum(py), pdfNum(w), pdfNum(h))
+}
+
+func (cv *pdfCanvas) image(x, y, w, h float64, img image.Image, back dvorak.Color) {
+	dots := func(v float64) int { return int(math.Round(v * float64(cv.dpi) / 72)) }
+	fit, err := art.Fit(img, back, dots(w), dots(h), art.Contain)
+	if err != nil {
+		return
+	}
+	name := cv.doc.addImage(fit)
+	px, py := cv.rect(x, y, h)
+	fmt.Fprintf(cv.content, "q %s 0 0 %s %s %s cm /%s Do Q\n", pdfNum(w), pdfNum(h), pdfNum(px), pdfNum(py), name)
+}
+
+func (cv *pdfCanvas) text(x, y, size float64, bold bool, c dvorak.Color, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	// The baseline is at Courier's ascent below the top of the line.
+	px, py := cv.rect(x, y, 0.8*size)
+	fmt.Fprintf(cv.content, "BT /%s %s Tf %s rg %s %s Td (%s) Tj ET\n",
+		font, pdfNum(size), pdfColor(c), pdfNum(px), pdfNum(py), pdfString(s))
+}
+
+func (cv *pdfCanvas) charWidth(size float64) float64 {
+	// Courier's characters are 0.6 em wide.
+	return 0.6 * size
+}
+
+// pdfNum formats v as a PDF number with at most two decimal places.
+func pdfNum(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// pdfColor formats c as the operands of the rg operator.
+func pdfColor(c dvorak.Color) string {
+	r, g, b := c.RGB()
+	return pdfNum(float64(r)/255) + " " + pdfNum(float64(g)/255) + " " + pdfNum(float64(b)/255)
+}
+
+// pdfStringReplacer escapes the characters that delimit PDF strings.
+var pdfStringReplacer = strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+
+// pdfString returns s escaped for use in a PDF literal string.
+func pdfString(s string) string {
+	return pdfStringReplacer.Replace(s)
+}
+
+// writePDF writes the cards of d to w as a PDF document with nine cards
+// on each A4 page, at their printed size. images maps Card.Image values
+// to the cards' art, which is embedded at dpi dots per inch, and credits,
+// if not nil, maps them to the information listed on the pages that
+// follow the cards.
+func writePDF(w io.Writer, d dvorak.Deck, images map[string]image.Image, credits map[string]dvorak.ImageInfo, dpi int) error {
+	doc := newPDFDoc()
+	// The cards are centered on the page with no space between them,
+	// so that they can be cut apart along shared edges.
+	left := (pageWidth - pageCols*cardWidth) / 2
+	top := pageHeight - (pageHeight-pageRows*cardHeight)/2
+	var content bytes.Buffer
+	for i, c := range d.Cards {
+		n := i % (pageCols * pageRows)
+		if n == 0 && i > 0 {
+			doc.addPage(content.Bytes())
+			content.Reset()
+		}
+		cv := &pdfCanvas{
+			doc:     doc,
+			content: &content,
+			dpi:     dpi,
+			x:       left + float64(n%pageCols)*cardWidth,
+			y:       top - float64(n/pageCols)*cardHeight,
+		}
+		drawCard(cv, c, images[c.Image])
+	}
+	if content.Len() > 0 || len(doc.pages) == 0 {
+		doc.addPage(content.Bytes())
+	}
+	if cv := creditViews(d.Cards, credits); len(cv) > 0 {
+		addCreditPages(doc, cv)
+	}
+	return doc.writeTo(w)
+}
+
+// The margin of a credits page and the font sizes of its text, in points.
+const (
+	creditMargin      = 36
+	creditHeadingSize = 14
+	creditSize        = 9
+)
+
+// addCreditPages adds pages to doc that list the credits of the
+// cards' images, as on the credits of an HTML page.
+func addCreditPages(doc *pdfDoc, credits []creditView) {
+	var content bytes.Buffer
+	cv := &pdfCanvas{doc: doc, content: &content, y: pageHeight}
+	cols := columns(cv, creditSize, pageWidth-2*creditMargin)
+	y := float64(creditMargin)
+	cv.text(creditMargin, y, creditHeadingSize, true, "000", "Image credits")
+	y += creditHeadingSize * 2
+	for _, cr := range credits {
+		lines := creditLines(cr, cols)
+		if h := float64(len(lines)+1) * creditSize * lineHeight; y+h > pageHeight-creditMargin {
+			doc.addPage(content.Bytes())
+			content.Reset()
+			y = creditMargin
+		}
+		for i, line := range lines {
+			cv.text(creditMargin, y, creditSize, i == 0, "000", line)
+			y += creditSize * lineHeight
+		}
+		y += creditSize * lineHeight
+	}
+	doc.addPage(content.Bytes())
+}
+
+// creditLines returns the lines of text that credit the image of cr's card,
+// wrapped at cols characters.
+func creditLines(cr creditView, cols int) []string {
+	cred := cr.Info.Credit
+	author := cred.Author
+	if author == "" {
+		author = cred.Credit
+	}
+	license := cred.License
+	switch {
+	case license == "":
+		license = cred.LicenseURL
+	case cred.LicenseURL != "":
+		license += " <" + cred.LicenseURL + ">"
+	}
+	lines := wrapText(asciiText(fmt.Sprintf("%d. %s", cr.Card.ID, plainLine(cr.Card.TitleText()))), cols)
+	for _, f := range []struct{ label, value string }{
+		{"Image", cr.Info.Name},
+		{"Author", author},
+		{"Licence", license},
+		{"Uploaded by", cred.Uploader},
+	} {
+		if f.value != "" {
+			lines = append(lines, wrapText(asciiText(f.label+": "+plainLine(f.value)), cols)...)
+		}
+	}
+	return lines
+}
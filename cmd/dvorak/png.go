@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/dkmccandless/dvorak"
+	"github.com/dkmccandless/dvorak/art"
+)
+
+// imageCanvas is a canvas that draws on an image
+// at a resolution of dpi dots per inch.
+type imageCanvas struct {
+	img *image.RGBA
+	dpi int
+}
+
+// newImageCanvas returns an imageCanvas the size of a card at dpi
+// dots per inch.
+func newImageCanvas(dpi int) *imageCanvas {
+	cv := &imageCanvas{dpi: dpi}
+	cv.img = image.NewRGBA(image.Rect(0, 0, cv.px(cardWidth), cv.px(cardHeight)))
+	return cv
+}
+
+// px returns the number of pixels that span v points.
+func (cv *imageCanvas) px(v float64) int {
+	return int(math.Round(v * float64(cv.dpi) / 72))
+}
+
+// rect returns the pixels of the rectangle with the given position and
+// size in points. Each edge is rounded separately, so that adjacent
+// rectangles neither overlap nor leave a gap.
+func (cv *imageCanvas) rect(x, y, w, h float64) image.Rectangle {
+	return image.Rect(cv.px(x), cv.px(y), cv.px(x+w), cv.px(y+h))
+}
+
+func (cv *imageCanvas) fill(x, y, w, h float64, c dvorak.Color) {
+	draw.Draw(cv.img, cv.rect(x, y, w, h), image.NewUniform(rgba(c)), image.Point{}, draw.Src)
+}
+
+func (cv *imageCanvas) image(x, y, w, h float64, img image.Image, back dvorak.Color) {
+	r := cv.rect(x, y, w, h)
+	fit, err := art.Fit(img, back, r.Dx(), r.Dy(), art.Contain)
+	if err != nil {
+		return
+	}
+	draw.Draw(cv.img, r, fit, image.Point{}, draw.Src)
+}
+
+func (cv *imageCanvas) text(x, y, size float64, bold bool, c dvorak.Color, s string) {
+	drawText(cv.img, cv.px(x), cv.px(y), s, cv.scale(size), bold, rgba(c))
+}
+
+func (cv *imageCanvas) charWidth(size float64) float64 {
+	return float64(glyphCols*cv.scale(size)) * 72 / float64(cv.dpi)
+}
+
+// scale returns the number of pixels to draw for each pixel of the font
+// at the given size, such that a character is no wider than in the
+// standard Courier font.
+func (cv *imageCanvas) scale(size float64) int {
+	// Courier's characters are 0.6 em wide.
+	s := int(0.6 * size * float64(cv.dpi) / 72 / glyphCols)
+	if s < 1 {
+		return 1
+	}
+	return s
+}
+
+// rgba returns c as an opaque color.RGBA.
+func rgba(c dvorak.Color) color.RGBA {
+	r, g, b := c.RGB()
+	return color.RGBA{r, g, b, 0xff}
+}
+
+// writePNGs writes each card of d to dir as ID.png, an image at dpi dots
+// per inch. images maps Card.Image values to the cards' art.
+func writePNGs(dir string, d dvorak.Deck, images map[string]image.Image, dpi int) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, c := range d.Cards {
+		cv := newImageCanvas(dpi)
+		drawCard(cv, c, images[c.Image])
+		name := filepath.Join(dir, fmt.Sprintf("%d.png", c.ID))
+		if err := writeOutput(name, func(w io.Writer) error { return art.Encode(w, cv.img, dpi) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+
+	"github.com/dkmccandless/dvorak"
+	"github.com/dkmccandless/dvorak/art"
+)
+
+func runRender(args []string) error {
+	fs := newFlagSet("render")
+	var o options
+	o.register(fs)
+	format := fs.String("format", "html", "output `format`: html, pdf (nine printed cards on each A4 page), or png (an image of each card); "+
+		"pdf and png print only ASCII and Latin-1 letters, without diacritics, and replace other characters with ?")
+	out := fs.String("o", "", "write to `file` instead of standard output, or for png, to `dir` (default cards)")
+	images := fs.Bool("images", true, "resolve the URLs of card images, or for pdf and png, draw them")
+	credits := fs.Bool("credits", false, "list the author and licence of each card image (html and pdf only)")
+	dpi := fs.Int("dpi", 300, "resolution of card images in pdf and png output in dots per inch")
+	fs.Parse(args)
+	o.configure()
+
+	switch *format {
+	case "html":
+	case "pdf", "png":
+		if *credits && *format == "png" {
+			return fmt.Errorf("-credits requires -format html or pdf")
+		}
+		if *dpi <= 0 {
+			return fmt.Errorf("invalid -dpi %d", *dpi)
+		}
+	default:
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+	d, err := o.load(deckArg(fs))
+	if err != nil {
+		return err
+	}
+
+	var infos map[string]dvorak.ImageInfo
+	if *credits {
+		if infos, err = dvorak.ImageCredits(d.Cards); err != nil {
+			return err
+		}
+	}
+
+	if *format != "html" {
+		warnPrintText(d, infos)
+		var arts map[string]image.Image
+		if *images {
+			if arts, err = o.loadArt(d.Cards, *dpi); err != nil {
+				return err
+			}
+		}
+		if *format == "png" {
+			dir := *out
+			if dir == "" {
+				dir = "cards"
+			}
+			return writePNGs(dir, d, arts, *dpi)
+		}
+		return writeOutput(*out, func(w io.Writer) error { return writePDF(w, d, arts, infos, *dpi) })
+	}
+
+	var urls map[string]string
+	if *images {
+		if urls, err = imageURLs(d.Cards, artWidth, artHeight); err != nil {
+			return err
+		}
+	}
+
+	return writeOutput(*out, func(w io.Writer) error { return writeHTML(w, d, urls, infos) })
+}
+
+// warnPrintText warns on standard error of the characters of the cards of d
+// and of their image credits that the pdf and png formats cannot print.
+func warnPrintText(d dvorak.Deck, credits map[string]dvorak.ImageInfo) {
+	for _, c := range d.Cards {
+		warnUnprintable(os.Stderr, fmt.Sprintf("card %d", c.ID),
+			c.TitleText(), c.TypeText(), dvorak.Card{Text: c.CornerValue}.PlainText(),
+			c.PlainText(), c.FlavorPlainText(), c.CreatorText())
+	}
+	for _, cr := range creditViews(d.Cards, credits) {
+		cred := cr.Info.Credit
+		warnUnprintable(os.Stderr, fmt.Sprintf("card %d: image credit", cr.Card.ID),
+			cr.Info.Name, cred.Author, cred.Credit, cred.License, cred.Uploader)
+	}
+}
+
+// loadArt returns the decoded images of cards, keyed by Card.Image,
+// downloaded at the size of a printed card's art box at dpi dots per inch.
+// Images that cannot be decoded, such as SVG images, are reported
+// and omitted.
+func (o *options) loadArt(cards []dvorak.Card, dpi int) (map[string]image.Image, error) {
+	width, height := art.Size(cardWidth/72.0, dpi), art.Size(artBoxHeight/72, dpi)
+	urls, err := imageURLs(cards, width, height)
+	if err != nil {
+		return nil, err
+	}
+	images := make(map[string]image.Image)
+	tried := make(map[string]bool)
+	for _, c := range cards {
+		u, ok := urls[c.Image]
+		if !ok || tried[c.Image] {
+			continue
+		}
+		tried[c.Image] = true
+		b, err := o.download(u)
+		if err != nil {
+			return nil, err
+		}
+		img, _, err := image.Decode(bytes.NewReader(b))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "card %d: image %q: %v\n", c.ID, c.Image, err)
+			continue
+		}
+		images[c.Image] = img
+	}
+	return images, nil
+}
+
+// writeOutput calls write with a Writer to the named file,
+// or to standard output if name is empty.
+func writeOutput(name string, write func(io.Writer) error) error {
+	if name == "" {
+		bw := bufio.NewWriter(os.Stdout)
+		if err := write(bw); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if err := write(bw); err != nil {
+		f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dkmccandless/dvorak"
+	"kr.dev/diff"
+)
+
+func TestWrapText(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		cols int
+		want []string
+	}{
+		{"", 10, nil},
+		{"Draw a card.", 20, []string{"Draw a card."}},
+		{"Draw a card. Discard a card.", 12, []string{"Draw a card.", "Discard a", "card."}},
+		{"Draw.\n\nDiscard.\n", 20, []string{"Draw.", "", "Discard."}},
+		{"Supercalifragilistic", 8, []string{"Supercal", "ifragili", "stic"}},
+	} {
+		diff.Test(t, t.Errorf, wrapText(tt.s, tt.cols), tt.want)
+	}
+}
+
+func TestASCIIText(t *testing.T) {
+	for _, tt := range []struct{ s, want string }{
+		{"Draw a card.", "Draw a card."},
+		{"“Don’t” – Émile…", `"Don't" - Emile...`},
+		{"Card\tgame\n☃", "Card game\n?"},
+	} {
+		if got := asciiText(tt.s); got != tt.want {
+			t.Errorf("asciiText(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWarnUnprintable(t *testing.T) {
+	for _, tt := range []struct {
+		ss   []string
+		want string
+	}{
+		{[]string{"Draw a card.", "“Émile” – ×2"}, ""},
+		{[]string{"Рыба", "Fish ☃", "Рак"}, "card 1: cannot print \"Рыба☃к\"; replaced with ?\n"},
+	} {
+		var b strings.Builder
+		warnUnprintable(&b, "card 1", tt.ss...)
+		if got := b.String(); got != tt.want {
+			t.Errorf("warnUnprintable(%q) wrote %q, want %q", tt.ss, got, tt.want)
+		}
+	}
+}
+
+// testDeck returns a deck of n cards, the first of which
+// has a red header and an image named A.png.
+func testDeck(n int) dvorak.Deck {
+	src := "{{card|title=First|type=Action|bgcolor=red|image=A.png|text=Steal (a) Thing.}}"
+	src += strings.Repeat("{{card|title=Other|type=Thing}}", n-1)
+	return dvorak.Deck{Name: "Test", Cards: dvorak.Parse([]byte(src))}
+}
+
+// greenImage returns a green image with the aspect ratio of a card's art box.
+func greenImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 50, 36))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{0, 0xff, 0, 0xff}), image.Point{}, draw.Src)
+	return img
+}
+
+func TestWritePNGs(t *testing.T) {
+	dir := t.TempDir()
+	art := greenImage()
+	d := testDeck(2)
+	if err := writePNGs(dir, d, map[string]image.Image{"A.png": art}, 144); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(dir, "1.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Bounds(), image.Rect(0, 0, 2*cardWidth, 2*cardHeight); got != want {
+		t.Errorf("bounds = %v, want %v", got, want)
+	}
+	for _, tt := range []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{2 * cardWidth / 2, 4, color.RGBA{0xff, 0, 0, 0xff}},       // header
+		{2 * cardWidth / 2, 2 * 100, color.RGBA{0, 0xff, 0, 0xff}}, // art
+		{1, 1, color.RGBA{0, 0, 0, 0xff}},                          // border
+	} {
+		r, g, b, a := img.At(tt.x, tt.y).RGBA()
+		if got := (color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}); got != tt.want {
+			t.Errorf("pixel (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2.png")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	var b bytes.Buffer
+	art := greenImage()
+	if err := writePDF(&b, testDeck(10), map[string]image.Image{"A.png": art}, nil, 72); err != nil {
+		t.Fatal(err)
+	}
+	pdf := b.Bytes()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("malformed PDF:\n%s", pdf)
+	}
+
+	// Each entry of the cross-reference table
+	// is the offset of the object with its number.
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point to xref", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj\n"; !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf("object %d: offset %d points to %.20q", i+1, off, pdf[off:])
+		}
+	}
+
+	if n := bytes.Count(pdf, []byte("/Type /Page ")); n != 2 {
+		t.Errorf("got %d pages, want 2", n)
+	}
+	if !bytes.Contains(pdf, []byte("/Count 2 ")) {
+		t.Error("page tree does not count 2 pages")
+	}
+	if n := bytes.Count(pdf, []byte("/Subtype /Image ")); n != 1 {
+		t.Errorf("got %d images, want 1", n)
+	}
+	content := pdfContent(t, pdf)
+	for _, s := range []string{"(First) Tj", `(Steal \(a\) Thing.) Tj`, "/Im1 Do", "1 0 0 rg"} {
+		if !strings.Contains(content, s) {
+			t.Errorf("content does not contain %q", s)
+		}
+	}
+}
+
+func TestWritePDFCredits(t *testing.T) {
+	credits := map[string]dvorak.ImageInfo{
+		"A.png": {
+			Name: "A.png",
+			Credit: &dvorak.ImageCredit{
+				Author:     "Alice (Émile)",
+				License:    "CC BY-SA 4.0",
+				LicenseURL: "https://creativecommons.org/licenses/by-sa/4.0",
+				Uploader:   "Binarius",
+			},
+		},
+	}
+	var b bytes.Buffer
+	if err := writePDF(&b, testDeck(2), nil, credits, 72); err != nil {
+		t.Fatal(err)
+	}
+	pdf := b.Bytes()
+	if n := bytes.Count(pdf, []byte("/Type /Page ")); n != 2 {
+		t.Errorf("got %d pages, want 2", n)
+	}
+	content := pdfContent(t, pdf)
+	for _, s := range []string{
+		"(Image credits) Tj",
+		"(1. First) Tj",
+		"(Image: A.png) Tj",
+		`(Author: Alice \(Emile\)) Tj`,
+		"(Licence: CC BY-SA 4.0 <https://creativecommons.org/licenses/by-sa/4.0>) Tj",
+		"(Uploaded by: Binarius) Tj",
+	} {
+		if !strings.Contains(content, s) {
+			t.Errorf("content does not contain %q", s)
+		}
+	}
+	if strings.Contains(content, "(2. Other) Tj") {
+		t.Error("credits list a card without an image")
+	}
+
+	b.Reset()
+	if err := writePDF(&b, testDeck(2), nil, nil, 72); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(pdfContent(t, b.Bytes()), "Image credits") {
+		t.Error("writePDF without credits: output contains credits")
+	}
+}
+
+// pdfContent returns the decompressed content of the streams of pdf
+// that are not images.
+func pdfContent(t *testing.T, pdf []byte) string {
+	var b strings.Builder
+	re := regexp.MustCompile(`(?s)<< /Filter /FlateDecode /Length (\d+) >>\nstream\n`)
+	for _, loc := range re.FindAllSubmatchIndex(pdf, -1) {
+		n, _ := strconv.Atoi(string(pdf[loc[2]:loc[3]]))
+		r, err := zlib.NewReader(bytes.NewReader(pdf[loc[1] : loc[1]+n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Write(s)
+	}
+	return b.String()
+}
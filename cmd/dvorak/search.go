@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dkmccandless/dvorak/search"
+)
+
+func runSearch(args []string) error {
+	fs := newFlagSet("search")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dvorak search [flags] <query> <deck>...")
+		fs.PrintDefaults()
+	}
+	var o options
+	o.register(fs)
+	limit := fs.Int("n", 20, "print at most `n` results, or all if 0")
+	fs.Parse(args)
+	o.configure()
+	if fs.NArg() < 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var ix search.Index
+	for _, deck := range fs.Args()[1:] {
+		d, err := o.load(deck)
+		if err != nil {
+			return err
+		}
+		ix.Add(d)
+	}
+	results, err := ix.Search(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+	for _, r := range results {
+		fmt.Printf("%s #%d %s (%s)\n", r.Deck, r.Card.ID, r.Card.TitleText(), r.Card.TypeText())
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dkmccandless/dvorak"
+	"github.com/dkmccandless/dvorak/search"
+)
+
+func runServe(args []string) error {
+	fs := newFlagSet("serve")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dvorak serve [flags] <deck>...")
+		fs.PrintDefaults()
+	}
+	var o options
+	o.register(fs)
+	addr := fs.String("addr", "localhost:8080", "listen on `address`")
+	images := fs.Bool("images", true, "resolve and serve the images of cards")
+	poll := fs.Duration("poll", 2*time.Second, "check local deck files for changes at this `interval`")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	// The client is configured once, before the handlers
+	// and the watch goroutine use it concurrently.
+	o.configure()
+
+	s := &server{o: &o, images: *images}
+	for _, deck := range fs.Args() {
+		if err := s.load(deck); err != nil {
+			return err
+		}
+	}
+	go s.watch(*poll)
+	log.Printf("serving %d decks on http://%s/", len(s.decks), *addr)
+	return http.ListenAndServe(*addr, s)
+}
+
+// A server serves a gallery of the cards of one or more decks.
+type server struct {
+	o *options
+
+	// images indicates whether to resolve and serve card images.
+	images bool
+
+	mu    sync.RWMutex
+	decks []*servedDeck
+
+	// index is the search index of the cards of decks.
+	index *search.Index
+
+	// imageURLs maps Card.Image values to URLs.
+	imageURLs map[string]string
+
+	// imageCache maps image URLs to the images downloaded
+	// from the wiki if the options specify no cache directory.
+	imageCache map[string][]byte
+}
+
+// A servedDeck is a deck loaded by a server.
+type servedDeck struct {
+	dvorak.Deck
+
+	// source is the URL or file name that the deck was loaded from.
+	source string
+
+	// modTime is the modification time of a local source file.
+	modTime time.Time
+}
+
+// load loads or reloads the deck named by source.
+func (s *server) load(source string) error {
+	var modTime time.Time
+	if !isURL(source) {
+		fi, err := os.Stat(source)
+		if err != nil {
+			return err
+		}
+		modTime = fi.ModTime()
+	}
+	d, err := s.o.load(source)
+	if err != nil {
+		return err
+	}
+	var urls map[string]string
+	if s.images {
+		if urls, err = imageURLs(d.Cards, artWidth, artHeight); err != nil {
+			log.Printf("%s: resolving images: %v", source, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Decks are served by name, so the decks' names must differ.
+	for _, old := range s.decks {
+		if old.source != source && old.Name == d.Name {
+			return fmt.Errorf("%s: deck name %q is also the name of %s", source, d.Name, old.source)
+		}
+	}
+	if s.imageURLs == nil {
+		s.imageURLs = make(map[string]string)
+	}
+	for name, url := range urls {
+		s.imageURLs[name] = url
+	}
+	sd := &servedDeck{Deck: d, source: source, modTime: modTime}
+	replaced := false
+	for i, old := range s.decks {
+		if old.source == source {
+			s.decks[i] = sd
+			replaced = true
+		}
+	}
+	if !replaced {
+		s.decks = append(s.decks, sd)
+	}
+	s.index = new(search.Index)
+	for _, d := range s.decks {
+		s.index.Add(d.Deck)
+	}
+	return nil
+}
+
+// watch reloads local deck files when they change, checking at each interval.
+func (s *server) watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		s.mu.RLock()
+		var changed []string
+		for _, d := range s.decks {
+			if d.modTime.IsZero() {
+				continue
+			}
+			if fi, err := os.Stat(d.source); err == nil && !fi.ModTime().Equal(d.modTime) {
+				changed = append(changed, d.source)
+			}
+		}
+		s.mu.RUnlock()
+
+		for _, source := range changed {
+			log.Printf("reloading %s", source)
+			if err := s.load(source); err != nil {
+				log.Printf("reloading %s: %v", source, err)
+			}
+		}
+	}
+}
+
+// ServeHTTP serves the gallery at "/", the cards of a deck at "/deck/NAME/",
+// a single card at "/deck/NAME/ID", and card images at "/image/FILENAME".
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch p := r.URL.Path; {
+	case p == "/":
+		s.serveGallery(w, r, nil)
+	case strings.HasPrefix(p, "/deck/"):
+		name, id, _ := strings.Cut(strings.TrimPrefix(p, "/deck/"), "/")
+		d := s.deck(name)
+		if d == nil {
+			http.NotFound(w, r)
+			return
+		}
+		if id == "" {
+			s.serveGallery(w, r, d)
+			return
+		}
+		s.serveCard(w, r, d, id)
+	case strings.HasPrefix(p, "/image/"):
+		s.serveImage(w, r, strings.TrimPrefix(p, "/image/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// deck returns the deck with the given name, or nil if there is none.
+func (s *server) deck(name string) *servedDeck {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, d := range s.decks {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// galleryPage is the data of the gallery template.
+type galleryPage struct {
+	// Deck is the name of the deck being shown, or empty for all decks.
+	Deck string
+
+	// Decks lists the names of all decks.
+	Decks []string
+
+	// Query is the search query.
+	Query string
+
+	Cards []cardView
+}
+
+// serveGallery serves the cards of d, or of all decks if d is nil,
+// that match the search query parameter q.
+func (s *server) serveGallery(w http.ResponseWriter, r *http.Request, d *servedDeck) {
+	q := r.FormValue("q")
+	page := galleryPage{Query: q}
+	if d != nil {
+		page.Deck = d.Name
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, sd := range s.decks {
+		page.Decks = append(page.Decks, sd.Name)
+	}
+	results, err := s.index.Search(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, res := range results {
+		if d == nil || res.Deck == d.Name {
+			page.Cards = append(page.Cards, s.view(res.Deck, res.Card))
+		}
+	}
+	s.execute(w, "gallery.html", page)
+}
+
+// cardPage is the data of the card page template.
+type cardPage struct {
+	Deck string
+	Card cardView
+}
+
+// serveCard serves the card of d with the given ID.
+func (s *server) serveCard(w http.ResponseWriter, r *http.Request, d *servedDeck, id string) {
+	n, err := strconv.Atoi(id)
+	if err != nil || n < 1 || n > len(d.Cards) {
+		http.NotFound(w, r)
+		return
+	}
+	s.mu.RLock()
+	page := cardPage{Deck: d.Name, Card: s.view(d.Name, d.Cards[n-1])}
+	s.mu.RUnlock()
+	s.execute(w, "cardpage.html", page)
+}
+
+// view returns the template data of c in the named deck.
+// s.mu must be held.
+func (s *server) view(deck string, c dvorak.Card) cardView {
+	v := cardView{
+		Card: c,
+		Link: "/deck/" + url.PathEscape(deck) + "/" + strconv.Itoa(c.ID),
+	}
+	if _, ok := s.imageURLs[c.Image]; ok {
+		v.ImageURL = "/image/" + url.PathEscape(c.Image)
+	}
+	return v
+}
+
+// serveImage serves the image with the given Card.Image value,
+// downloading it from the wiki if it is not cached.
+func (s *server) serveImage(w http.ResponseWriter, r *http.Request, name string) {
+	s.mu.RLock()
+	u, ok := s.imageURLs[name]
+	b, cached := s.imageCache[u]
+	s.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if !cached {
+		var err error
+		b, err = s.o.download(u)
+		var se *dvorak.HTTPStatusError
+		if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		// Without a cache directory, images are cached in memory
+		// so that each is downloaded only once.
+		if s.o.cache == "" {
+			s.mu.Lock()
+			if s.imageCache == nil {
+				s.imageCache = make(map[string][]byte)
+			}
+			s.imageCache[u] = b
+			s.mu.Unlock()
+		}
+	}
+	ct := mime.TypeByExtension(filepath.Ext(path.Base(u)))
+	if ct == "" {
+		ct = http.DetectContentType(b)
+	}
+	w.Header().Set("Content-Type", ct)
+	w.Header().Set("Cache-Control", "max-age=86400")
+	w.Write(b)
+}
+
+// execute executes the named template with data.
+func (s *server) execute(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("executing %s: %v", name, err)
+	}
+}
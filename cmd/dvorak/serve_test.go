@@ -0,0 +1,148 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "Fish Deck.wiki")
+	if err := os.WriteFile(file, []byte("{{card|title=Fishing Rod|type=Action|text=Gain control of a fish.}}{{card|title=Moon|type=Thing}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := &server{o: &options{}}
+	if err := s.load(file); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		path       string
+		status     int
+		want, omit string
+	}{
+		{"/", http.StatusOK, "Fishing Rod", ""},
+		{"/?q=FISHING", http.StatusOK, "Fishing Rod", "Moon"},
+		{"/?q=nothing", http.StatusOK, "No cards found.", "Moon"},
+		{"/?q=type:thing", http.StatusOK, "Moon", "Fishing Rod"},
+		{"/?q=color:red", http.StatusBadRequest, "", ""},
+		{"/deck/Fish%20Deck/", http.StatusOK, "Moon", ""},
+		{"/deck/Fish%20Deck/2", http.StatusOK, "<title>Moon - Fish Deck</title>", "Fishing Rod"},
+		{"/deck/Fish%20Deck/3", http.StatusNotFound, "", ""},
+		{"/deck/Bird%20Deck/", http.StatusNotFound, "", ""},
+		{"/image/Fish.png", http.StatusNotFound, "", ""},
+		{"/other", http.StatusNotFound, "", ""},
+	} {
+		resp, err := http.Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("GET %s: status %v, want %v", tt.path, resp.StatusCode, tt.status)
+		}
+		if !strings.Contains(string(b), tt.want) {
+			t.Errorf("GET %s: body does not contain %q", tt.path, tt.want)
+		}
+		if tt.omit != "" && strings.Contains(string(b), tt.omit) {
+			t.Errorf("GET %s: body contains %q", tt.path, tt.omit)
+		}
+	}
+
+	// Reloading replaces the deck.
+	if err := os.WriteFile(file, []byte("{{card|title=Sun}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.load(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.decks) != 1 || s.decks[0].Cards[0].TitleText() != "Sun" {
+		t.Errorf("after reload: got %+v", s.decks)
+	}
+
+	// A deck with the same name as another is rejected.
+	other := filepath.Join(dir, "other", "Fish Deck.wiki")
+	if err := os.MkdirAll(filepath.Dir(other), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("{{card|title=Bird}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.load(other); err == nil {
+		t.Errorf("loading %s: got nil error for a duplicate deck name", other)
+	}
+	if len(s.decks) != 1 {
+		t.Errorf("after duplicate: got %d decks, want 1", len(s.decks))
+	}
+}
+
+func TestServeImageCache(t *testing.T) {
+	var requests int32
+	wiki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("GIF89a"))
+	}))
+	defer wiki.Close()
+
+	s := &server{o: &options{}, imageURLs: map[string]string{"Fish.gif": wiki.URL + "/images/Fish.gif"}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(ts.URL + "/image/Fish.gif")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(b) != "GIF89a" {
+			t.Errorf("GET /image/Fish.gif: got %v %q", resp.Status, b)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("downloaded the image %d times, want 1", n)
+	}
+}
+
+func TestServeImagesConcurrently(t *testing.T) {
+	content := "GIF89a" + strings.Repeat("x", 1<<16)
+	wiki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer wiki.Close()
+
+	s := &server{o: &options{cache: t.TempDir()}, imageURLs: map[string]string{
+		"A.gif": wiki.URL + "/images/A.gif",
+		"B.gif": wiki.URL + "/images/B.gif",
+	}}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		name := []string{"A.gif", "B.gif"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(ts.URL + "/image/" + name)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			b, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || string(b) != content {
+				t.Errorf("GET /image/%s: got %v and %d bytes, want %d", name, resp.Status, len(b), len(content))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+// options holds the flags shared by the commands that load decks.
+type options struct {
+	// cache is the directory in which images and the revisions of decks
+	// read with rev or at are stored. If empty, nothing is cached.
+	cache string
+
+	// timeout limits the duration of each HTTP request.
+	timeout time.Duration
+
+	// interval is the minimum time between requests to the wiki.
+	interval time.Duration
+
+	// rev is the revision ID at which to read decks from the wiki,
+	// or 0 for the current revision.
+	rev int64
+
+	// at is the time at which to read decks from the wiki,
+	// or empty for the current revision.
+	at string
+
+	// site is the base URL of the wiki, or empty for dvorak.DefaultSite.
+	site string
+}
+
+// register defines the flags of o in fs.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.cache, "cache", "", "cache images, and decks read with -rev or -at, in `dir`")
+	o.registerClient(fs)
+	fs.Int64Var(&o.rev, "rev", 0, "read decks from the wiki as of revision `id`")
+	fs.StringVar(&o.at, "at", "", "read decks from the wiki as of `time` (RFC 3339)")
+}
+
+// registerClient defines the flags of o that configure the client
+// in fs, for commands that query the wiki but do not load decks.
+func (o *options) registerClient(fs *flag.FlagSet) {
+	fs.DurationVar(&o.timeout, "timeout", 30*time.Second, "time limit for each HTTP request")
+	fs.DurationVar(&o.interval, "interval", dvorak.DefaultClient.Interval, "minimum time between requests to the wiki")
+	fs.StringVar(&o.site, "site", "", "read from the wiki or mirror at base `url` instead of the Dvorak wiki")
+}
+
+// site returns the Site of dvorak.DefaultClient.
+func site() *dvorak.Site {
+	if s := dvorak.DefaultClient.Site; s != nil {
+		return s
+	}
+	return dvorak.DefaultSite
+}
+
+// useSite makes dvorak.DefaultClient read from the wiki at the base URL
+// site, if it is not empty. The wiki also accepts the URLs of pages
+// on the default site, so that it may be used as a mirror.
+func useSite(site string) {
+	if site == "" || site == dvorak.DefaultSite.URL {
+		return
+	}
+	var hosts []string
+	if u, err := url.Parse(dvorak.DefaultSite.URL); err == nil {
+		hosts = append(hosts, u.Host)
+	}
+	dvorak.DefaultClient.Site = &dvorak.Site{URL: site, Hosts: hosts}
+}
+
+// isURL reports whether deck names a wiki page rather than a local file.
+func isURL(deck string) bool {
+	return strings.HasPrefix(deck, "http://") || strings.HasPrefix(deck, "https://")
+}
+
+// deckName returns the name of a deck: the last element of its page title,
+// or its file name without any extension.
+func deckName(deck string) string {
+	if isURL(deck) {
+		if ref, err := site().ParseURL(deck); err == nil && ref.Title != "" {
+			return path.Base(ref.Title)
+		}
+		u, err := url.Parse(deck)
+		if err != nil {
+			return deck
+		}
+		return strings.ReplaceAll(path.Base(u.Path), "_", " ")
+	}
+	base := filepath.Base(deck)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// configure applies o to dvorak.DefaultClient, which loads decks and images.
+// It must be called once, before any request is made.
+func (o *options) configure() {
+	dvorak.DefaultClient.HTTPClient = &http.Client{Timeout: o.timeout}
+	dvorak.DefaultClient.Interval = o.interval
+	useSite(o.site)
+}
+
+// source returns the source code of deck.
+// Decks read from the wiki at a revision or time are stored in and loaded
+// from the cache. The current revision is always fetched, because the
+// cache cannot tell when it changes.
+func (o *options) source(deck string) ([]byte, error) {
+	if !isURL(deck) {
+		return os.ReadFile(deck)
+	}
+	switch {
+	case o.rev != 0 && o.at != "":
+		return nil, fmt.Errorf("-rev and -at are mutually exclusive")
+	case o.rev != 0:
+		key := fmt.Sprintf("deck %s rev %d", deck, o.rev)
+		return o.cached(key, func() ([]byte, error) { return dvorak.GetRevision(deck, o.rev) })
+	case o.at != "":
+		t, err := time.Parse(time.RFC3339, o.at)
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("deck %s at %s", deck, t.UTC().Format(time.RFC3339))
+		return o.cached(key, func() ([]byte, error) { return dvorak.GetAt(deck, t) })
+	}
+	return dvorak.Get(deck)
+}
+
+// load returns the Deck named by deck.
+func (o *options) load(deck string) (dvorak.Deck, error) {
+	b, err := o.source(deck)
+	if err != nil {
+		return dvorak.Deck{}, err
+	}
+	return dvorak.Deck{Name: deckName(deck), Cards: dvorak.Parse(b)}, nil
+}
+
+// download returns the body of the resource at url,
+// storing it in and loading it from the cache.
+func (o *options) download(url string) ([]byte, error) {
+	return o.cached("url "+url, func() ([]byte, error) { return dvorak.DefaultClient.Download(url) })
+}
+
+// cached returns the cached value stored under key if there is one.
+// Otherwise it calls fetch and caches the result if fetch succeeds.
+func (o *options) cached(key string, fetch func() ([]byte, error)) ([]byte, error) {
+	if o.cache == "" {
+		return fetch()
+	}
+	if o.site != "" && o.site != dvorak.DefaultSite.URL {
+		key = "site " + o.site + " " + key
+	}
+	sum := sha256.Sum256([]byte(key))
+	name := filepath.Join(o.cache, hex.EncodeToString(sum[:]))
+	if b, err := os.ReadFile(name); err == nil {
+		return b, nil
+	}
+	b, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	return b, writeFileAtomic(name, b)
+}
+
+// writeFileAtomic writes b to the named file by renaming a temporary file,
+// so that concurrent readers never see a partially written file.
+func writeFileAtomic(name string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), name); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDeckName(t *testing.T) {
+	for _, tt := range []struct{ deck, want string }{
+		{"https://dvorakgame.co.uk/index.php/Cthulhu_Deck", "Cthulhu Deck"},
+		{"http://dvorakgame.co.uk/index.php/Pirate_Deck?action=raw", "Pirate Deck"},
+		{"https://dvorakgame.co.uk/index.php?title=pirate_Deck&oldid=3", "Pirate Deck"},
+		{"https://dvorakgame.co.uk/wiki/Pirate_Deck/Cards_1-100#Top", "Cards 1-100"},
+		{"decks/pirate.wiki", "pirate"},
+		{"pirate", "pirate"},
+	} {
+		if got := deckName(tt.deck); got != tt.want {
+			t.Errorf("deckName(%q): got %q, want %q", tt.deck, got, tt.want)
+		}
+	}
+}
+
+func TestCached(t *testing.T) {
+	o := options{cache: t.TempDir()}
+	calls := 0
+	fetch := func() ([]byte, error) {
+		calls++
+		return []byte("content"), nil
+	}
+	for i := 0; i < 2; i++ {
+		b, err := o.cached("key", fetch)
+		if err != nil || string(b) != "content" {
+			t.Fatalf("cached: got %q, %v; want %q, nil", b, err, "content")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("cached: fetch called %d times, want 1", calls)
+	}
+
+	errFetch := errors.New("fetch failed")
+	if _, err := o.cached("other", func() ([]byte, error) { return nil, errFetch }); err != errFetch {
+		t.Errorf("cached: got error %v, want %v", err, errFetch)
+	}
+}
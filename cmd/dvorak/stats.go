@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func runStats(args []string) error {
+	fs := newFlagSet("stats")
+	var o options
+	o.register(fs)
+	format := fs.String("format", "text", "output `format`: text, json, or html")
+	out := fs.String("o", "", "write to `file` instead of standard output")
+	fs.Parse(args)
+	o.configure()
+
+	var write func(s dvorak.DeckStats, w io.Writer) error
+	switch *format {
+	case "text":
+		write = dvorak.DeckStats.WriteText
+	case "json":
+		write = dvorak.DeckStats.WriteJSON
+	case "html":
+		write = dvorak.DeckStats.WriteHTML
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	d, err := o.load(deckArg(fs))
+	if err != nil {
+		return err
+	}
+	s := dvorak.Stats(d)
+	return writeOutput(*out, func(w io.Writer) error { return write(s, w) })
+}
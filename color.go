@@ -0,0 +1,219 @@
+package dvorak
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Color is a color in canonical form: a three- or six-digit hex triplet
+// with uppercase digits and no leading "#". The three-digit form is used
+// whenever it represents the color exactly.
+type Color string
+
+// ParseColor parses a color written as a three- or six-digit hex triplet
+// with or without a leading "#", a CSS color name, or a CSS rgb() or rgba()
+// function with integer or percentage arguments, and returns it in
+// canonical form.
+//
+// The arguments of rgb() may be separated by commas or by spaces, and may
+// include an alpha value, as in "rgb(255 0 0 / 50%)". Because a Color is
+// opaque, a translucent color is blended with white, the background on
+// which cards are drawn. A fully transparent color, including the CSS
+// keyword "transparent", is the empty Color, which denotes no color.
+func ParseColor(s string) (Color, error) {
+	t := strings.ToLower(strings.TrimSpace(s))
+	if t == "transparent" {
+		return "", nil
+	}
+	if hex, ok := namedColors[t]; ok {
+		return canonicalColor(hex), nil
+	}
+	for _, fn := range []string{"rgb(", "rgba("} {
+		if strings.HasPrefix(t, fn) && strings.HasSuffix(t, ")") {
+			return parseRGB(t[len(fn) : len(t)-1])
+		}
+	}
+	t = strings.TrimPrefix(t, "#")
+	if len(t) != 3 && len(t) != 6 {
+		return "", fmt.Errorf("invalid color %q", s)
+	}
+	if _, err := strconv.ParseUint(t, 16, 32); err != nil {
+		return "", fmt.Errorf("invalid color %q", s)
+	}
+	if len(t) == 3 {
+		return Color(strings.ToUpper(t)), nil
+	}
+	return canonicalColor(t), nil
+}
+
+// parseRGB parses the arguments of a CSS rgb() function.
+func parseRGB(s string) (Color, error) {
+	invalid := fmt.Errorf("invalid color %q", "rgb("+s+")")
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	args := split(s)
+	if i := strings.Index(s, "/"); i >= 0 {
+		// The alpha value follows a slash.
+		args = split(s[:i])
+		if a := split(s[i+1:]); len(a) == 1 && len(args) == 3 {
+			args = append(args, a[0])
+		} else {
+			return "", invalid
+		}
+	} else if len(args) == 4 && strings.Count(s, ",") != 3 {
+		// In the legacy syntax, the alpha value follows a fourth comma.
+		return "", invalid
+	}
+	if len(args) != 3 && len(args) != 4 {
+		return "", invalid
+	}
+	alpha := 1.0
+	if len(args) == 4 {
+		a, ok := parseComponent(args[3], 1)
+		if !ok {
+			return "", invalid
+		}
+		alpha = a
+	}
+	if alpha == 0 {
+		return "", nil
+	}
+	var hex strings.Builder
+	for _, a := range args[:3] {
+		v, ok := parseComponent(a, 255)
+		if !ok {
+			return "", invalid
+		}
+		v = v*alpha + 255*(1-alpha)
+		fmt.Fprintf(&hex, "%02x", int(math.Round(v)))
+	}
+	return canonicalColor(hex.String()), nil
+}
+
+// parseComponent parses a number between 0 and max,
+// or a percentage of max.
+func parseComponent(s string, max float64) (float64, bool) {
+	var v float64
+	var err error
+	if strings.HasSuffix(s, "%") {
+		v, err = strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		v = v * max / 100
+	} else {
+		v, err = strconv.ParseFloat(s, 64)
+	}
+	return v, err == nil && v >= 0 && v <= max
+}
+
+// canonicalColor returns the canonical form of a valid six-digit hex triplet.
+func canonicalColor(hex string) Color {
+	hex = strings.ToUpper(hex)
+	if hex[0] == hex[1] && hex[2] == hex[3] && hex[4] == hex[5] {
+		return Color(hex[0:1] + hex[2:3] + hex[4:5])
+	}
+	return Color(hex)
+}
+
+// RGB returns the red, green, and blue components of c.
+// If c is not in canonical form, RGB returns zero values.
+func (c Color) RGB() (r, g, b uint8) {
+	s := string(c)
+	if len(s) == 3 {
+		s = s[0:1] + s[0:1] + s[1:2] + s[1:2] + s[2:3] + s[2:3]
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if len(s) != 6 || err != nil {
+		return 0, 0, 0
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v)
+}
+
+// CSS returns c in CSS hex notation, or the empty string if c is empty.
+func (c Color) CSS() string {
+	if c == "" {
+		return ""
+	}
+	return "#" + string(c)
+}
+
+// Contrast returns black or white, whichever is more legible as the color
+// of text written on a background of color c.
+func (c Color) Contrast() Color {
+	// https://www.w3.org/TR/WCAG21/#dfn-contrast-ratio
+	l := c.luminance()
+	if (l+0.05)/0.05 >= 1.05/(l+0.05) {
+		return "000"
+	}
+	return "FFF"
+}
+
+// luminance returns the relative luminance of c.
+// https://www.w3.org/TR/WCAG21/#dfn-relative-luminance
+func (c Color) luminance() float64 {
+	linear := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	r, g, b := c.RGB()
+	return 0.2126*linear(r) + 0.7152*linear(g) + 0.0722*linear(b)
+}
+
+// namedColors maps the CSS color names to six-digit hex triplets.
+// https://www.w3.org/TR/css-color-4/#named-colors
+var namedColors = map[string]string{
+	"aliceblue": "f0f8ff", "antiquewhite": "faebd7", "aqua": "00ffff",
+	"aquamarine": "7fffd4", "azure": "f0ffff", "beige": "f5f5dc",
+	"bisque": "ffe4c4", "black": "000000", "blanchedalmond": "ffebcd",
+	"blue": "0000ff", "blueviolet": "8a2be2", "brown": "a52a2a",
+	"burlywood": "deb887", "cadetblue": "5f9ea0", "chartreuse": "7fff00",
+	"chocolate": "d2691e", "coral": "ff7f50", "cornflowerblue": "6495ed",
+	"cornsilk": "fff8dc", "crimson": "dc143c", "cyan": "00ffff",
+	"darkblue": "00008b", "darkcyan": "008b8b", "darkgoldenrod": "b8860b",
+	"darkgray": "a9a9a9", "darkgreen": "006400", "darkgrey": "a9a9a9",
+	"darkkhaki": "bdb76b", "darkmagenta": "8b008b", "darkolivegreen": "556b2f",
+	"darkorange": "ff8c00", "darkorchid": "9932cc", "darkred": "8b0000",
+	"darksalmon": "e9967a", "darkseagreen": "8fbc8f", "darkslateblue": "483d8b",
+	"darkslategray": "2f4f4f", "darkslategrey": "2f4f4f", "darkturquoise": "00ced1",
+	"darkviolet": "9400d3", "deeppink": "ff1493", "deepskyblue": "00bfff",
+	"dimgray": "696969", "dimgrey": "696969", "dodgerblue": "1e90ff",
+	"firebrick": "b22222", "floralwhite": "fffaf0", "forestgreen": "228b22",
+	"fuchsia": "ff00ff", "gainsboro": "dcdcdc", "ghostwhite": "f8f8ff",
+	"gold": "ffd700", "goldenrod": "daa520", "gray": "808080",
+	"green": "008000", "greenyellow": "adff2f", "grey": "808080",
+	"honeydew": "f0fff0", "hotpink": "ff69b4", "indianred": "cd5c5c",
+	"indigo": "4b0082", "ivory": "fffff0", "khaki": "f0e68c",
+	"lavender": "e6e6fa", "lavenderblush": "fff0f5", "lawngreen": "7cfc00",
+	"lemonchiffon": "fffacd", "lightblue": "add8e6", "lightcoral": "f08080",
+	"lightcyan": "e0ffff", "lightgoldenrodyellow": "fafad2", "lightgray": "d3d3d3",
+	"lightgreen": "90ee90", "lightgrey": "d3d3d3", "lightpink": "ffb6c1",
+	"lightsalmon": "ffa07a", "lightseagreen": "20b2aa", "lightskyblue": "87cefa",
+	"lightslategray": "778899", "lightslategrey": "778899", "lightsteelblue": "b0c4de",
+	"lightyellow": "ffffe0", "lime": "00ff00", "limegreen": "32cd32",
+	"linen": "faf0e6", "magenta": "ff00ff", "maroon": "800000",
+	"mediumaquamarine": "66cdaa", "mediumblue": "0000cd", "mediumorchid": "ba55d3",
+	"mediumpurple": "9370db", "mediumseagreen": "3cb371", "mediumslateblue": "7b68ee",
+	"mediumspringgreen": "00fa9a", "mediumturquoise": "48d1cc", "mediumvioletred": "c71585",
+	"midnightblue": "191970", "mintcream": "f5fffa", "mistyrose": "ffe4e1",
+	"moccasin": "ffe4b5", "navajowhite": "ffdead", "navy": "000080",
+	"oldlace": "fdf5e6", "olive": "808000", "olivedrab": "6b8e23",
+	"orange": "ffa500", "orangered": "ff4500", "orchid": "da70d6",
+	"palegoldenrod": "eee8aa", "palegreen": "98fb98", "paleturquoise": "afeeee",
+	"palevioletred": "db7093", "papayawhip": "ffefd5", "peachpuff": "ffdab9",
+	"peru": "cd853f", "pink": "ffc0cb", "plum": "dda0dd",
+	"powderblue": "b0e0e6", "purple": "800080", "rebeccapurple": "663399",
+	"red": "ff0000", "rosybrown": "bc8f8f", "royalblue": "4169e1",
+	"saddlebrown": "8b4513", "salmon": "fa8072", "sandybrown": "f4a460",
+	"seagreen": "2e8b57", "seashell": "fff5ee", "sienna": "a0522d",
+	"silver": "c0c0c0", "skyblue": "87ceeb", "slateblue": "6a5acd",
+	"slategray": "708090", "slategrey": "708090", "snow": "fffafa",
+	"springgreen": "00ff7f", "steelblue": "4682b4", "tan": "d2b48c",
+	"teal": "008080", "thistle": "d8bfd8", "tomato": "ff6347",
+	"turquoise": "40e0d0", "violet": "ee82ee", "wheat": "f5deb3",
+	"white": "ffffff", "whitesmoke": "f5f5f5", "yellow": "ffff00",
+	"yellowgreen": "9acd32",
+}
@@ -0,0 +1,82 @@
+package dvorak
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+	for _, tt := range []struct {
+		s     string
+		want  Color
+		isErr bool
+	}{
+		{"600", "600", false},
+		{"#600", "600", false},
+		{" fff ", "FFF", false},
+		{"FFD700", "FFD700", false},
+		{"#ffd700", "FFD700", false},
+		{"660000", "600", false},
+		{"red", "F00", false},
+		{"Gold", "FFD700", false},
+		{"rgb(255, 215, 0)", "FFD700", false},
+		{"rgb(100% 0% 0%)", "F00", false},
+		{"rgb(255 0 0 / 50%)", "FF8080", false},
+		{"rgb(255 0 0 / 1)", "F00", false},
+		{"rgba(255, 0, 0, 0.5)", "FF8080", false},
+		{"rgb(255 0 0 / 0)", "", false},
+		{"transparent", "", false},
+		{"Transparent", "", false},
+		{"", "", true},
+		{"#", "", true},
+		{"60", "", true},
+		{"6000", "", true},
+		{"GGG", "", true},
+		{"+FF", "", true},
+		{"garbage", "", true},
+		{"rgb(256, 0, 0)", "", true},
+		{"rgb(1, 2)", "", true},
+		{"rgb(255 0 0 / 2)", "", true},
+		{"rgb(255 0 / 0 0)", "", true},
+		{"rgb(255 0 0 0)", "", true},
+		{"rgb(1, 2, 3, 4, 5)", "", true},
+	} {
+		got, err := ParseColor(tt.s)
+		if got != tt.want || (err != nil) != tt.isErr {
+			t.Errorf("ParseColor(%q): got %q, %v; want %q, error=%v",
+				tt.s, got, err, tt.want, tt.isErr,
+			)
+		}
+	}
+}
+
+func TestColorRGB(t *testing.T) {
+	for _, tt := range []struct {
+		c       Color
+		r, g, b uint8
+	}{
+		{"", 0, 0, 0},
+		{"600", 0x66, 0, 0},
+		{"FFD700", 0xff, 0xd7, 0},
+		{"fish", 0, 0, 0},
+	} {
+		if r, g, b := tt.c.RGB(); r != tt.r || g != tt.g || b != tt.b {
+			t.Errorf("%q.RGB(): got %v, %v, %v; want %v, %v, %v",
+				tt.c, r, g, b, tt.r, tt.g, tt.b,
+			)
+		}
+	}
+}
+
+func TestContrast(t *testing.T) {
+	for _, tt := range []struct{ c, want Color }{
+		{actionRed, "FFF"},
+		{thingBlue, "FFF"},
+		{otherGray, "FFF"},
+		{"000", "FFF"},
+		{"FFF", "000"},
+		{"FFD700", "000"},
+		{"0F0", "000"},
+	} {
+		if got := tt.c.Contrast(); got != tt.want {
+			t.Errorf("%q.Contrast(): got %q, want %q", tt.c, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package dvorak
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// columns lists the CSV and TSV column names in order.
+var columns = cardParams
+
+// WriteCSV writes cards to w as comma-separated values,
+// beginning with a header row of template parameter names.
+// Fields that hold HTML are written as wikitext.
+func WriteCSV(w io.Writer, cards []Card) error {
+	return writeTable(csv.NewWriter(w), cards)
+}
+
+// WriteTSV writes cards to w as tab-separated values.
+// The format is otherwise the same as that of WriteCSV.
+func WriteTSV(w io.Writer, cards []Card) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = '\t'
+	return writeTable(cw, cards)
+}
+
+// ReadCSV returns the Cards described by the comma-separated values in r.
+//
+// The first row names the template parameter of each column.
+// Columns may appear in any order, and columns with unrecognized names
+// are ignored. Each following row is a card, which is populated in the
+// same way as a card template read by Parse, including its links and
+// image file links. Empty rows are skipped.
+func ReadCSV(r io.Reader) ([]Card, error) {
+	p, err := readTable(csvReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return p.cards, nil
+}
+
+// ReadTSV returns the Cards described by the tab-separated values in r.
+// The format is otherwise the same as that of ReadCSV.
+func ReadTSV(r io.Reader) ([]Card, error) {
+	p, err := readTable(tsvReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return p.cards, nil
+}
+
+// DiagnoseCSV returns the problems that ReadCSV works around in the Cards
+// in r. The Line of each Diagnostic is the line on which the card's row
+// begins, and unrecognized columns are reported as unknown parameters.
+func DiagnoseCSV(r io.Reader) ([]Diagnostic, error) {
+	p, err := readTable(csvReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return p.diagnostics, nil
+}
+
+// DiagnoseTSV is like DiagnoseCSV, but for ReadTSV.
+func DiagnoseTSV(r io.Reader) ([]Diagnostic, error) {
+	p, err := readTable(tsvReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return p.diagnostics, nil
+}
+
+// csvReader returns a reader of comma-separated values from r.
+func csvReader(r io.Reader) *csv.Reader {
+	return csv.NewReader(r)
+}
+
+// tsvReader returns a reader of tab-separated values from r.
+func tsvReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.LazyQuotes = true
+	return cr
+}
+
+// writeTable writes a header row and a row for each card to w.
+func writeTable(w *csv.Writer, cards []Card) error {
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	for _, c := range cards {
+		if err := w.Write(cardRecord(c)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// cardRecord returns the fields of c in the order of columns.
+func cardRecord(c Card) []string {
+	return []string{
+		wikitext(c.Title),
+		wikitext(c.Type),
+		wikitext(c.Text),
+		string(c.BGColor),
+		wikitext(c.CornerValue),
+		c.Image,
+		string(c.ImgBack),
+		wikitext(c.FlavorText),
+		wikitext(c.Creator),
+		flagValue(c.LongTitle),
+		flagValue(c.LongText),
+		flagValue(c.MiniCard),
+	}
+}
+
+// flagValue returns the template parameter value of a boolean field.
+func flagValue(b bool) string {
+	if b {
+		return "y"
+	}
+	return ""
+}
+
+// readTable reads a header row and the rows of cards that follow it.
+// The cards, their diagnostics, and the line of each row are returned
+// as a page.
+func readTable(r *csv.Reader) (*page, error) {
+	r.FieldsPerRecord = -1
+	p := &page{}
+	header, err := r.Read()
+	if err == io.EOF {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return p, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		if len(record) > len(header) {
+			return nil, fmt.Errorf("line %d: %d fields, header has %d", line, len(record), len(header))
+		}
+		params := make(map[string]string)
+		var images []string
+		for i, value := range record {
+			// As in a template, links are replaced with their text,
+			// and a link to a file sets the card's image.
+			text, files := replaceLinks(value)
+			images = append(images, files...)
+			if text = strings.TrimSpace(text); text != "" {
+				params[header[i]] = text
+			}
+		}
+		for _, name := range images {
+			params["image"] = name
+		}
+		if len(params) == 0 {
+			continue
+		}
+		id := len(p.cards) + 1
+		p.cards = append(p.cards, newCard(params, id, nil))
+		p.lines = append(p.lines, line)
+		p.diagnostics = append(p.diagnostics, checkCard(params, id, line)...)
+	}
+}
@@ -0,0 +1,161 @@
+package dvorak
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const csvDeck = `{{card|title=A|type=Action|text='''Action:''' Draw a card.<br>Discard a card.|creator=Binarius}}
+{{card|title=B, the Thing|type=Thing|bgcolor=090|image=B.png|imgback=FFD700|longtitle=y}}
+{{card|title=C|cornervalue=4|flavortext=''"Blub."''|minicard=y|longtext=y}}`
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteCSV(&b, Parse([]byte(csvDeck))); err != nil {
+		t.Fatal(err)
+	}
+	want := `title,type,text,bgcolor,cornervalue,image,imgback,flavortext,creator,longtitle,longtext,minicard
+A,Action,'''Action:''' Draw a card.<br>Discard a card.,600,,,,,Binarius,,,
+"B, the Thing",Thing,,090,,B.png,FFD700,,,y,,
+C,,,666,4,,,"''""Blub.""''",,,y,y
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteCSV:\ngot\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	cards := Parse([]byte(csvDeck))
+	for _, tt := range []struct {
+		name  string
+		write func(*bytes.Buffer, []Card) error
+		read  func(*bytes.Buffer) ([]Card, error)
+	}{
+		{
+			"CSV",
+			func(b *bytes.Buffer, c []Card) error { return WriteCSV(b, c) },
+			func(b *bytes.Buffer) ([]Card, error) { return ReadCSV(b) },
+		},
+		{
+			"TSV",
+			func(b *bytes.Buffer, c []Card) error { return WriteTSV(b, c) },
+			func(b *bytes.Buffer) ([]Card, error) { return ReadTSV(b) },
+		},
+	} {
+		var b bytes.Buffer
+		if err := tt.write(&b, cards); err != nil {
+			t.Fatalf("Write%v: %v", tt.name, err)
+		}
+		got, err := tt.read(&b)
+		if err != nil {
+			t.Fatalf("Read%v: %v", tt.name, err)
+		}
+		if len(got) != len(cards) {
+			t.Fatalf("Read%v: got %d cards, want %d", tt.name, len(got), len(cards))
+		}
+		for i := range cards {
+			var g, w bytes.Buffer
+			WriteCSV(&g, got[i:i+1])
+			WriteCSV(&w, cards[i:i+1])
+			if g.String() != w.String() || got[i].ID != cards[i].ID {
+				t.Errorf("Read%v card %d: got %v, want %v", tt.name, i, g.String(), w.String())
+			}
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	for _, tt := range []struct {
+		s     string
+		cards []Card
+		isErr bool
+	}{
+		{"", nil, false},
+		{"title,type\n", nil, false},
+		{
+			"Type, Title ,unknown\nAction,A,x\n,,\nThing,B,\n",
+			[]Card{
+				{Title: text("A"), Type: text("Action"), BGColor: actionRed, ID: 1},
+				{Title: text("B"), Type: text("Thing"), BGColor: thingBlue, ID: 2},
+			},
+			false,
+		},
+		{"title\nA,B\n", nil, true},
+		{"title\n\"A\n", nil, true},
+	} {
+		cards, err := ReadCSV(strings.NewReader(tt.s))
+		if isErr := err != nil; isErr != tt.isErr {
+			t.Errorf("ReadCSV(%q): error %v, want error=%v", tt.s, err, tt.isErr)
+		}
+		var g, w bytes.Buffer
+		WriteCSV(&g, cards)
+		WriteCSV(&w, tt.cards)
+		if g.String() != w.String() {
+			t.Errorf("ReadCSV(%q): got %v, want %v", tt.s, g.String(), w.String())
+		}
+	}
+}
+
+func TestReadCSVLinks(t *testing.T) {
+	const s = `title,text,creator
+A,Draw a [[Card|card]]. [[File:Fish.png|thumb]],"[[User:Binarius|Binarius]] ([[User talk:Binarius|talk]]) 12:00, 1 January 2021 (UTC)"
+`
+	cards, err := ReadCSV(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Parse([]byte("{{card|title=A|text=Draw a [[Card|card]]. [[File:Fish.png|thumb]]|creator=[[User:Binarius|Binarius]] ([[User talk:Binarius|talk]]) 12:00, 1 January 2021 (UTC)}}"))
+	var g, w bytes.Buffer
+	WriteCSV(&g, cards)
+	WriteCSV(&w, want)
+	if g.String() != w.String() {
+		t.Errorf("ReadCSV: got\n%v\nwant\n%v", g.String(), w.String())
+	}
+	if len(cards) == 1 && (cards[0].CreatorText() != "Binarius" || cards[0].Image != "Fish.png") {
+		t.Errorf("ReadCSV: got creator %q and image %q, want Binarius and Fish.png", cards[0].CreatorText(), cards[0].Image)
+	}
+}
+
+func TestDiagnoseCSV(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		diagnose func(string) ([]Diagnostic, error)
+		s        string
+	}{
+		{"CSV", func(s string) ([]Diagnostic, error) { return DiagnoseCSV(strings.NewReader(s)) }, "title,bgcolor,notes\nA,,\nB,nocolor,x\n"},
+		{"TSV", func(s string) ([]Diagnostic, error) { return DiagnoseTSV(strings.NewReader(s)) }, "title\tbgcolor\tnotes\nA\t\t\nB\tnocolor\tx\n"},
+	} {
+		diags, err := tt.diagnose(tt.s)
+		if err != nil {
+			t.Fatalf("Diagnose%v: %v", tt.name, err)
+		}
+		var got []string
+		for _, d := range diags {
+			got = append(got, fmt.Sprintf("%v line %d card %d %s", d.Kind, d.Line, d.Card, d.Param))
+		}
+		want := []string{"unknown-param line 3 card 2 notes", "invalid-color line 3 card 2 bgcolor"}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("Diagnose%v: got %q, want %q", tt.name, got, want)
+		}
+	}
+}
+
+func TestWriteCSVTemplateValues(t *testing.T) {
+	cards := Parse([]byte(`{{card|title=A&#124;B|text=1 &#61; 1 &#123;&#123;x&#125;&#125; <span title="a&#124;b">c</span>}}`))
+	if got, want := cards[0].TitleText(), "A|B"; got != want {
+		t.Fatalf("TitleText: got %q, want %q", got, want)
+	}
+	record := cardRecord(cards[0])
+	for _, v := range record {
+		if strings.ContainsAny(v, "|{}") {
+			t.Errorf("field %q contains a template delimiter", v)
+		}
+	}
+	// The fields can be pasted into a card template.
+	pasted := Parse([]byte("{{card|title=" + record[0] + "|text=" + record[2] + "}}"))
+	if len(pasted) != 1 || dump(pasted[0].Title) != dump(cards[0].Title) || dump(pasted[0].Text) != dump(cards[0].Text) {
+		t.Errorf("pasted template: got %v, want %v", pasted, cards)
+	}
+}
@@ -0,0 +1,152 @@
+package dvorak
+
+import (
+	"net/url"
+	"sort"
+	"time"
+)
+
+// DeckCategory is the wiki category of deck pages.
+const DeckCategory = "Category:Decks"
+
+// cardTemplate is the title of the wiki's card template.
+const cardTemplate = "Template:Card"
+
+// A DeckInfo describes a deck page on the wiki.
+type DeckInfo struct {
+	// Title is the title of the deck's page.
+	Title string `json:"title"`
+
+	// URL is the URL of the deck's page.
+	URL string `json:"url"`
+
+	// Cards is the number of cards on the deck's page and its subpages.
+	Cards int `json:"cards"`
+
+	// Modified is the time of the latest revision of the deck's page
+	// or any of its subpages.
+	Modified time.Time `json:"modified"`
+}
+
+// ListDecks returns the decks on the wiki in order of title.
+// It uses DefaultClient.
+func ListDecks() ([]DeckInfo, error) { return DefaultClient.ListDecks() }
+
+// ListDecks returns the decks on the wiki in order of title.
+// A deck is a page in DeckCategory or a page that uses the card template,
+// except that cards on a subpage of another such page, such as
+// "Beta Deck/Part 1", are counted as cards of that page. A title that
+// merely contains a slash, such as "AC/DC Deck", is a deck of its own
+// unless the part before the slash is also such a page.
+func (c *Client) ListDecks() ([]DeckInfo, error) {
+	members, err := c.queryList("categorymembers", "cm", url.Values{
+		"cmtitle":     {DeckCategory},
+		"cmnamespace": {"0"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	embedders, err := c.queryList("embeddedin", "ei", url.Values{
+		"eititle":     {cardTemplate},
+		"einamespace": {"0"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	decks := make(map[string]*DeckInfo)
+	// parts lists the titles of each deck's page and subpages.
+	parts := make(map[string][]string)
+	add := func(deck, title string) {
+		if decks[deck] == nil {
+			decks[deck] = &DeckInfo{Title: deck, URL: c.site().PageURL(deck)}
+		}
+		if !containsString(parts[deck], title) {
+			parts[deck] = append(parts[deck], title)
+		}
+	}
+	isPage := make(map[string]bool)
+	for _, title := range append(members, embedders...) {
+		isPage[title] = true
+	}
+	for _, title := range members {
+		add(title, title)
+	}
+	for _, title := range embedders {
+		add(parentDeck(title, isPage), title)
+	}
+
+	var titles []string
+	for _, ts := range parts {
+		titles = append(titles, ts...)
+	}
+	sort.Strings(titles)
+	pages, err := c.latestRevisions(titles)
+	if err != nil {
+		return nil, err
+	}
+	for deck, ts := range parts {
+		d := decks[deck]
+		for _, title := range ts {
+			p, ok := pages[title]
+			if !ok {
+				continue
+			}
+			d.Cards += len(parsePage([]byte(p.Content), nil).cards)
+			if p.Revision.Timestamp.After(d.Modified) {
+				d.Modified = p.Revision.Timestamp
+			}
+		}
+	}
+
+	list := make([]DeckInfo, 0, len(decks))
+	for _, d := range decks {
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Title < list[j].Title })
+	return list, nil
+}
+
+// parentDeck returns the title of the deck that the page with the given
+// title is part of: its first ancestor in isPage, in the sense of
+// MediaWiki subpages, or title itself if it has none.
+func parentDeck(title string, isPage map[string]bool) string {
+	for i := 0; i < len(title); i++ {
+		if title[i] == '/' && isPage[title[:i]] {
+			return title[:i]
+		}
+	}
+	return title
+}
+
+// listQuery is the relevant part of the MediaWiki API's list query results.
+type listQuery struct {
+	Continue map[string]string
+	Query    map[string][]struct {
+		Title string
+	}
+}
+
+// queryList returns the titles of the pages in the named list,
+// whose parameters have the given prefix, following continuations.
+func (c *Client) queryList(list, prefix string, params url.Values) ([]string, error) {
+	params.Set("action", "query")
+	params.Set("list", list)
+	params.Set(prefix+"limit", "max")
+	var titles []string
+	for {
+		var lq listQuery
+		if err := c.queryAPI(params, &lq); err != nil {
+			return nil, err
+		}
+		for _, p := range lq.Query[list] {
+			titles = append(titles, p.Title)
+		}
+		if lq.Continue == nil {
+			return titles, nil
+		}
+		for k, v := range lq.Continue {
+			params.Set(k, v)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package dvorak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dkmccandless/dvorak/dvoraktest"
+	"kr.dev/diff"
+)
+
+func TestListDecks(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	// Limit the results of each list request to two pages,
+	// so that ListDecks must continue the queries.
+	s.MaxLimit = 2
+	at := func(month int) dvoraktest.Revision {
+		return dvoraktest.Revision{Timestamp: time.Date(2021, time.Month(month), 1, 0, 0, 0, 0, time.UTC)}
+	}
+	add := func(title string, month int, content string) {
+		r := at(month)
+		r.Content = content
+		s.AddRevision(title, r)
+	}
+	add("Alpha Deck", 1, "{{card|title=A}}{{card|title=B}}[[Category:Decks]]")
+	add("Beta Deck", 2, "{{subpage|page=Part 1}}{{card|title=C}}")
+	add("Beta Deck/Part 1", 3, "{{card|title=D}}{{card|title=E}}")
+	add("Empty Deck", 4, "Coming soon. [[Category:Decks]]")
+	add("AC/DC Deck", 5, "{{card|title=F}}")
+	add("AC/DC Deck/Part 1", 6, "{{card|title=G}}")
+	add("Other Page", 7, "Not a deck.")
+	c := &Client{HTTPClient: s.Client()}
+
+	got, err := c.ListDecks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := DefaultSite.IndexURL()
+	want := []DeckInfo{
+		{
+			Title:    "AC/DC Deck",
+			URL:      base + "/AC/DC_Deck",
+			Cards:    2,
+			Modified: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:    "Alpha Deck",
+			URL:      base + "/Alpha_Deck",
+			Cards:    2,
+			Modified: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:    "Beta Deck",
+			URL:      base + "/Beta_Deck",
+			Cards:    3,
+			Modified: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:    "Empty Deck",
+			URL:      base + "/Empty_Deck",
+			Modified: time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	diff.Test(t, t.Errorf, got, want)
+}
+
+func TestParentDeck(t *testing.T) {
+	isPage := map[string]bool{"Beta Deck": true, "Beta Deck/Part 1": true, "AC/DC Deck": true}
+	for _, tt := range []struct{ title, want string }{
+		{"Beta Deck", "Beta Deck"},
+		{"Beta Deck/Part 1", "Beta Deck"},
+		{"Beta Deck/Part 1/Extra", "Beta Deck"},
+		{"AC/DC Deck", "AC/DC Deck"},
+		{"AC/DC Deck/Part 1", "AC/DC Deck"},
+		{"/Slash", "/Slash"},
+	} {
+		if got := parentDeck(tt.title, isPage); got != tt.want {
+			t.Errorf("parentDeck(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
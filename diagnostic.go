@@ -0,0 +1,170 @@
+package dvorak
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// A DiagnosticKind classifies a Diagnostic.
+type DiagnosticKind int
+
+const (
+	// InvalidColor is a bgcolor or imgback value that is not a valid color.
+	InvalidColor DiagnosticKind = iota + 1
+
+	// UnknownParam is a parameter that the card template does not use.
+	UnknownParam
+
+	// UnbalancedHTML is a parameter value with unclosed or unexpected
+	// HTML tags.
+	UnbalancedHTML
+)
+
+func (k DiagnosticKind) String() string {
+	switch k {
+	case InvalidColor:
+		return "invalid-color"
+	case UnknownParam:
+		return "unknown-param"
+	case UnbalancedHTML:
+		return "unbalanced-html"
+	default:
+		return "unknown"
+	}
+}
+
+// A Diagnostic describes a problem with a card template
+// that Parse works around, for example by using a default value.
+type Diagnostic struct {
+	Kind DiagnosticKind
+
+	// Line is the line number of the start of the card template.
+	Line int
+
+	// Card is the ID of the card.
+	Card int
+
+	// Param is the name of the template parameter at fault, if any.
+	Param string
+
+	// Msg describes the problem.
+	Msg string
+}
+
+func (d Diagnostic) String() string {
+	if d.Param == "" {
+		return fmt.Sprintf("line %d: card %d: %s", d.Line, d.Card, d.Msg)
+	}
+	return fmt.Sprintf("line %d: card %d: %s: %s", d.Line, d.Card, d.Param, d.Msg)
+}
+
+// Diagnose returns the problems that Parse works around in the Cards in b.
+func Diagnose(b []byte) []Diagnostic {
+	return parsePage(b, nil).diagnostics
+}
+
+// CardLines returns the line number in b of the template of each Card
+// that Parse returns, in order.
+func CardLines(b []byte) []int {
+	return parsePage(b, nil).lines
+}
+
+// richParams lists the card template parameters whose values are HTML.
+var richParams = []string{"title", "type", "text", "cornervalue", "flavortext", "creator"}
+
+// checkCard returns the Diagnostics of the card with the given ID
+// described by params, whose template begins at the given line.
+func checkCard(params map[string]string, id, line int) []Diagnostic {
+	var diags []Diagnostic
+	add := func(kind DiagnosticKind, param, msg string) {
+		diags = append(diags, Diagnostic{Kind: kind, Line: line, Card: id, Param: param, Msg: msg})
+	}
+
+	var unknown []string
+	for name := range params {
+		if name != "" && !containsString(cardParams, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		add(UnknownParam, name, "unknown parameter")
+	}
+
+	for _, name := range []string{"bgcolor", "imgback"} {
+		if v := params[name]; v != "" {
+			if _, err := ParseColor(v); err != nil {
+				add(InvalidColor, name, err.Error())
+			}
+		}
+	}
+
+	for _, name := range richParams {
+		if msg := checkTags(params[name]); msg != "" {
+			add(UnbalancedHTML, name, msg)
+		}
+	}
+	return diags
+}
+
+// optionalEndTags is the set of HTML elements whose end tags may be omitted.
+var optionalEndTags = map[string]bool{
+	"dd": true, "dt": true, "li": true, "option": true, "p": true,
+	"td": true, "th": true, "tr": true,
+}
+
+// checkTags describes the first unclosed or unexpected HTML tag in s,
+// or returns the empty string if its tags are balanced.
+func checkTags(s string) string {
+	var open []string
+	z := html.NewTokenizer(strings.NewReader(escapeNonTags(s)))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				if !optionalEndTags[open[i]] {
+					return fmt.Sprintf("unclosed <%s>", open[i])
+				}
+			}
+			return ""
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if !voidElements[string(name)] {
+				open = append(open, string(name))
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if voidElements[string(name)] {
+				continue
+			}
+			i := len(open) - 1
+			for i >= 0 && open[i] != string(name) {
+				if !optionalEndTags[open[i]] {
+					break
+				}
+				i--
+			}
+			switch {
+			case i >= 0 && open[i] == string(name):
+				open = open[:i]
+			case containsString(open, string(name)):
+				return fmt.Sprintf("unclosed <%s>", open[i])
+			default:
+				return fmt.Sprintf("unexpected </%s>", name)
+			}
+		}
+	}
+}
+
+// containsString reports whether a contains s.
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package dvorak
+
+import "testing"
+
+func TestCheckTags(t *testing.T) {
+	for _, tt := range []struct{ s, want string }{
+		{"", ""},
+		{"Draw a card.", ""},
+		{"<b>Action:</b> Draw.<br>Discard.<br/>", ""},
+		{"<font color=FFD700>Gold</font>", ""},
+		{"<ul><li>One<li>Two</ul>", ""},
+		{"<p>One<p>Two", ""},
+		{"Replace <metal> with a metal", ""},
+		{"<b>Action:", "unclosed <b>"},
+		{"<b><i>Both</b>", "unclosed <i>"},
+		{"Draw.</b>", "unexpected </b>"},
+		{"<div><li>One</div>", ""},
+		{"<span><b>One</span></b>", "unclosed <b>"},
+	} {
+		if got := checkTags(tt.s); got != tt.want {
+			t.Errorf("checkTags(%q): got %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	for _, tt := range []struct {
+		d    Diagnostic
+		want string
+	}{
+		{Diagnostic{Line: 3, Card: 2, Msg: "problem"}, "line 3: card 2: problem"},
+		{
+			Diagnostic{Kind: InvalidColor, Line: 3, Card: 2, Param: "bgcolor", Msg: `invalid color "x"`},
+			`line 3: card 2: bgcolor: invalid color "x"`,
+		},
+	} {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String(): got %q, want %q", got, tt.want)
+		}
+	}
+}
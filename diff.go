@@ -0,0 +1,332 @@
+package dvorak
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A ChangeKind is the kind of a CardChange.
+type ChangeKind int
+
+const (
+	// Added indicates a card that is only in the new deck.
+	Added ChangeKind = iota
+
+	// Removed indicates a card that is only in the old deck.
+	Removed
+
+	// Changed indicates a card that is in both decks
+	// but has moved or has different fields.
+	Changed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// A CardChange describes how a card differs between two versions of a deck.
+type CardChange struct {
+	Kind ChangeKind `json:"kind"`
+
+	// OldID and NewID are the card's IDs in the old and new decks.
+	// OldID is 0 if the card was added, and NewID is 0 if it was removed.
+	OldID int `json:"oldID,omitempty"`
+	NewID int `json:"newID,omitempty"`
+
+	// Title is the plain text of the card's title in the new deck,
+	// or in the old deck if the card was removed.
+	Title string `json:"title"`
+
+	// Moved indicates that the card's position relative to the other
+	// cards in both decks has changed.
+	Moved bool `json:"moved,omitempty"`
+
+	// Fields lists the fields that differ, in template parameter order.
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// A FieldChange is a difference in the value of one field of a card.
+type FieldChange struct {
+	// Field is the name of the card template parameter.
+	Field string `json:"field"`
+
+	// Old and New are the field's values as template parameter wikitext.
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// A DeckDiff lists the changes between two versions of a deck
+// in the order of the new deck, with each removed card following
+// the card that preceded it in the old deck.
+type DeckDiff []CardChange
+
+// minSimilarity is the minimum similarity of the contents of two cards
+// with different titles for Diff to consider them the same card.
+const minSimilarity = 0.5
+
+// Diff returns the changes from the cards of an old version of a deck
+// to those of a new version. Cards are matched by title, and cards whose
+// titles have changed are matched by the similarity of their contents.
+// IDs are not used for matching, since they change whenever a card is
+// inserted or removed. Unchanged cards are omitted.
+func Diff(from, to []Card) DeckDiff {
+	match := make([]int, len(from)) // index in to of each card in from, or -1
+	matched := make([]bool, len(to))
+	for i := range match {
+		match[i] = -1
+	}
+
+	// Match cards with equal titles in order.
+	byTitle := make(map[string][]int)
+	for j, c := range to {
+		if t := strings.ToLower(c.TitleText()); t != "" {
+			byTitle[t] = append(byTitle[t], j)
+		}
+	}
+	for i, c := range from {
+		t := strings.ToLower(c.TitleText())
+		if js := byTitle[t]; t != "" && len(js) > 0 {
+			match[i], matched[js[0]] = js[0], true
+			byTitle[t] = js[1:]
+		}
+	}
+
+	// Match the remaining cards by decreasing similarity.
+	type pair struct {
+		i, j int
+		sim  float64
+	}
+	var pairs []pair
+	for i, c := range from {
+		if match[i] >= 0 {
+			continue
+		}
+		ci := contentWords(c)
+		for j, d := range to {
+			if matched[j] {
+				continue
+			}
+			if sim := similarity(ci, contentWords(d)); sim >= minSimilarity {
+				pairs = append(pairs, pair{i, j, sim})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].sim > pairs[b].sim })
+	for _, p := range pairs {
+		if match[p.i] < 0 && !matched[p.j] {
+			match[p.i], matched[p.j] = p.j, true
+		}
+	}
+
+	// Cards that are not in the longest sequence of matched cards
+	// in the same order in both decks have moved.
+	inv := make([]int, len(to)) // index in from of each card in to, or -1
+	for j := range inv {
+		inv[j] = -1
+	}
+	for i, j := range match {
+		if j >= 0 {
+			inv[j] = i
+		}
+	}
+	var seq []int
+	for _, i := range inv {
+		if i >= 0 {
+			seq = append(seq, i)
+		}
+	}
+	stay := increasing(seq)
+
+	var d DeckDiff
+	removed := func(i int) {
+		for ; i < len(from) && match[i] < 0; i++ {
+			d = append(d, CardChange{Kind: Removed, OldID: from[i].ID, Title: from[i].TitleText()})
+		}
+	}
+	removed(0)
+	for j, c := range to {
+		i := inv[j]
+		if i < 0 {
+			d = append(d, CardChange{Kind: Added, NewID: c.ID, Title: c.TitleText()})
+			continue
+		}
+		ch := CardChange{
+			Kind:   Changed,
+			OldID:  from[i].ID,
+			NewID:  c.ID,
+			Title:  c.TitleText(),
+			Moved:  !stay[i],
+			Fields: diffFields(from[i], c),
+		}
+		if ch.Moved || len(ch.Fields) > 0 {
+			d = append(d, ch)
+		}
+		removed(i + 1)
+	}
+	return d
+}
+
+// diffFields returns the fields that differ between a and b.
+func diffFields(a, b Card) []FieldChange {
+	var fields []FieldChange
+	ra, rb := cardRecord(a), cardRecord(b)
+	for k, name := range columns {
+		if ra[k] != rb[k] {
+			fields = append(fields, FieldChange{Field: name, Old: ra[k], New: rb[k]})
+		}
+	}
+	return fields
+}
+
+// contentWords returns the set of lowercase words
+// in the title, type, and text of c.
+func contentWords(c Card) map[string]bool {
+	words := make(map[string]bool)
+	for _, s := range []string{c.TitleText(), c.TypeText(), c.PlainText()} {
+		for _, w := range strings.Fields(strings.ToLower(s)) {
+			words[w] = true
+		}
+	}
+	return words
+}
+
+// similarity returns the Jaccard index of the sets a and b.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	var n int
+	for w := range a {
+		if b[w] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(a)+len(b)-n)
+}
+
+// increasing returns the set of elements of a longest increasing
+// subsequence of seq, whose elements are distinct.
+func increasing(seq []int) map[int]bool {
+	// tails[k] is the index in seq of the smallest last element
+	// of an increasing subsequence of length k+1.
+	var tails []int
+	prev := make([]int, len(seq))
+	for n, v := range seq {
+		k := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= v })
+		prev[n] = -1
+		if k > 0 {
+			prev[n] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, n)
+		} else {
+			tails[k] = n
+		}
+	}
+	set := make(map[int]bool)
+	if len(tails) == 0 {
+		return set
+	}
+	for n := tails[len(tails)-1]; n >= 0; n = prev[n] {
+		set[seq[n]] = true
+	}
+	return set
+}
+
+// WriteText writes d to w as a plain text report.
+// Each card is marked with "+" if added, "-" if removed, or "~" if changed,
+// and is followed by its changed fields.
+func (d DeckDiff) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, c := range d {
+		switch c.Kind {
+		case Added:
+			fmt.Fprintf(&b, "+ card %d: %s\n", c.NewID, c.Title)
+		case Removed:
+			fmt.Fprintf(&b, "- card %d: %s\n", c.OldID, c.Title)
+		default:
+			fmt.Fprintf(&b, "~ card %d -> %d: %s", c.OldID, c.NewID, c.Title)
+			if c.Moved {
+				b.WriteString(" (moved)")
+			}
+			b.WriteString("\n")
+		}
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "    %s:\n", f.Field)
+			if f.Old != "" {
+				fmt.Fprintf(&b, "      - %s\n", f.Old)
+			}
+			if f.New != "" {
+				fmt.Fprintf(&b, "      + %s\n", f.New)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes d to w as a JSON array.
+func (d DeckDiff) WriteJSON(w io.Writer) error {
+	if d == nil {
+		d = DeckDiff{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(d)
+}
+
+var diffTemplate = template.Must(template.New("diff").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Deck changes</title>
+<style>
+table { border-collapse: collapse; }
+td, th { padding: 0.2em 0.6em; text-align: left; vertical-align: top; }
+.added { background: #dfd; }
+.removed { background: #fdd; }
+del { background: #fdd; }
+ins { background: #dfd; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1>Deck changes</h1>
+{{if not .}}<p>No changes.</p>
+{{else}}<table>
+<tr><th>Old</th><th>New</th><th>Title</th><th>Changes</th></tr>
+{{range .}}<tr class="{{.Kind}}">
+<td>{{with .OldID}}{{.}}{{end}}</td>
+<td>{{with .NewID}}{{.}}{{end}}</td>
+<td>{{.Title}}</td>
+<td>{{.Kind}}{{if .Moved}}, moved{{end}}{{with .Fields}}
+<table>
+{{range .}}<tr><th>{{.Field}}</th><td>{{with .Old}}<del>{{.}}</del>{{end}}</td><td>{{with .New}}<ins>{{.}}</ins>{{end}}</td></tr>
+{{end}}</table>{{end}}</td>
+</tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes d to w as an HTML page.
+func (d DeckDiff) WriteHTML(w io.Writer) error {
+	return diffTemplate.Execute(w, d)
+}
@@ -0,0 +1,103 @@
+package dvorak
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"kr.dev/diff"
+)
+
+func TestDiff(t *testing.T) {
+	from := Parse([]byte(`{{card|title=Moon|type=Thing - Moon|text=Draw a card.}}
+{{card|title=Zap|type=Action|text=Destroy a Thing.}}
+{{card|title=Gone|type=Action|text=Something else entirely.}}
+{{card|title=Cheese|type=Thing|text=Discard your hand and draw three cards.|bgcolor=060}}
+{{card|title=Letter|type=Letter}}`))
+	to := Parse([]byte(`{{card|title=New|type=Action|text=Brand new.}}
+{{card|title=Moon|type=Thing - Moon|text=Draw two cards.|image=Moon.png}}
+{{card|title=Letter|type=Letter}}
+{{card|title=Zap|type=Action|text=Destroy a Thing.}}
+{{card|title=Big Cheese|type=Thing|text=Discard your hand and draw three cards.}}`))
+
+	want := DeckDiff{
+		{Kind: Added, NewID: 1, Title: "New"},
+		{Kind: Changed, OldID: 1, NewID: 2, Title: "Moon", Fields: []FieldChange{
+			{Field: "text", Old: "Draw a card.", New: "Draw two cards."},
+			{Field: "image", New: "Moon.png"},
+		}},
+		{Kind: Changed, OldID: 5, NewID: 3, Title: "Letter", Moved: true},
+		{Kind: Removed, OldID: 3, Title: "Gone"},
+		{Kind: Changed, OldID: 4, NewID: 5, Title: "Big Cheese", Fields: []FieldChange{
+			{Field: "title", Old: "Cheese", New: "Big Cheese"},
+			{Field: "bgcolor", Old: "060", New: "006"},
+		}},
+	}
+	diff.Test(t, t.Errorf, Diff(from, to), want)
+
+	if d := Diff(from, from); d != nil {
+		t.Errorf("Diff(from, from): got %v, want nil", d)
+	}
+}
+
+func TestIncreasing(t *testing.T) {
+	for _, tt := range []struct {
+		seq  []int
+		want map[int]bool
+	}{
+		{nil, map[int]bool{}},
+		{[]int{0, 1, 2}, map[int]bool{0: true, 1: true, 2: true}},
+		{[]int{0, 3, 1, 2}, map[int]bool{0: true, 1: true, 2: true}},
+		{[]int{2, 1, 0}, map[int]bool{0: true}},
+	} {
+		diff.Test(t, t.Errorf, increasing(tt.seq), tt.want)
+	}
+}
+
+func TestDeckDiffWrite(t *testing.T) {
+	d := DeckDiff{
+		{Kind: Added, NewID: 1, Title: "New"},
+		{Kind: Removed, OldID: 3, Title: "Gone"},
+		{Kind: Changed, OldID: 1, NewID: 2, Title: "Moon", Moved: true, Fields: []FieldChange{
+			{Field: "text", Old: "Draw a card.", New: "Draw two cards."},
+		}},
+	}
+
+	var b bytes.Buffer
+	if err := d.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	wantText := `+ card 1: New
+- card 3: Gone
+~ card 1 -> 2: Moon (moved)
+    text:
+      - Draw a card.
+      + Draw two cards.
+`
+	if b.String() != wantText {
+		t.Errorf("WriteText: got\n%s\nwant\n%s", b.String(), wantText)
+	}
+
+	b.Reset()
+	if err := d.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0]["kind"] != "added" || got[2]["moved"] != true {
+		t.Errorf("WriteJSON: got %s", b.Bytes())
+	}
+
+	b.Reset()
+	if err := d.WriteHTML(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<tr class="removed">`, "<del>Draw a card.</del>", "<ins>Draw two cards.</ins>"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteHTML: missing %q in\n%s", want, b.String())
+		}
+	}
+}
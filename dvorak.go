@@ -3,10 +3,6 @@ package dvorak
 
 import (
 	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"net/url"
 	"strings"
 )
 
@@ -17,32 +13,43 @@ type page struct {
 
 	// cards lists the page's cards.
 	cards []Card
+
+	// diagnostics lists problems with the page's cards.
+	diagnostics []Diagnostic
+
+	// lines lists the source line number of each card.
+	lines []int
 }
 
 // Get returns the source code of a Dvorak deck,
 // beginning with its subpages in order, if any.
-func Get(rawURL string) ([]byte, error) {
-	u, err := url.Parse(rawURL)
+// It uses DefaultClient.
+func Get(rawURL string) ([]byte, error) { return DefaultClient.Get(rawURL) }
+
+// Get returns the source code of a Dvorak deck,
+// beginning with its subpages in order, if any.
+// rawURL may be any URL of the deck's page that Site.ParseURL accepts
+// for the Client's Site, and the deck is read from the Site's URL.
+// If rawURL specifies a revision, Get is like GetRevision.
+func (c *Client) Get(rawURL string) ([]byte, error) {
+	ref, err := c.site().ParseURL(rawURL)
 	if err != nil {
 		return nil, err
 	}
-	if host := u.Hostname(); strings.ToLower(host) != "dvorakgame.co.uk" {
-		return nil, fmt.Errorf("invalid host %q", host)
+	if ref.RevID != 0 {
+		return c.getRevision(ref.Title, ref.RevID)
 	}
-	u.RawQuery = "action=raw"
-	path := u.EscapedPath()
 
-	main, err := readPage(u.String())
+	main, err := c.readPage(c.site().rawURL(ref.Title))
 	if err != nil {
-		return nil, err
+		return nil, pageError(err, ref.Title)
 	}
 	var b []byte
-	for _, sp := range parsePage(main).subpages {
-		log.Print(sp.page)
-		u.Path = path + "/" + sp.page
-		sb, err := readPage(u.String())
+	for _, sp := range parsePage(main, nil).subpages {
+		title := canonicalTitle(ref.Title + "/" + sp.page)
+		sb, err := c.readPage(c.site().rawURL(title))
 		if err != nil {
-			return nil, err
+			return nil, &SubpageError{Title: title, Err: pageError(err, title)}
 		}
 		b = append(b, sb...)
 	}
@@ -50,33 +57,40 @@ func Get(rawURL string) ([]byte, error) {
 	return append(b, main...), nil
 }
 
-// readPage returns the body of the page at url.
-// It returns an error if url cannot be accessed or read from.
-func readPage(url string) ([]byte, error) {
-	r, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	if r.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%v: status %v", url, r.StatusCode)
-	}
-	defer r.Body.Close()
+// Parse returns the Cards in b.
+func Parse(b []byte) []Card {
+	return parsePage(b, nil).cards
+}
 
-	return io.ReadAll(r.Body)
+// A Parser parses Dvorak wiki source code.
+// The zero value is ready to use and parses like Parse.
+type Parser struct {
+	// ColorRules determines the default background colors of cards
+	// that do not specify one. If nil, DefaultColorRules is used.
+	ColorRules ColorRules
 }
 
 // Parse returns the Cards in b.
-func Parse(b []byte) []Card {
-	return parsePage(b).cards
+func (p *Parser) Parse(b []byte) []Card {
+	return parsePage(b, p.ColorRules).cards
+}
+
+// Diagnose returns the problems that p.Parse works around in the Cards in b.
+func (p *Parser) Diagnose(b []byte) []Diagnostic {
+	return parsePage(b, p.ColorRules).diagnostics
 }
 
 // parsePage parses a page of wiki source code.
-func parsePage(b []byte) *page {
+// If rules is nil, DefaultColorRules is used.
+func parsePage(b []byte, rules ColorRules) *page {
 	p := &page{}
 
-	s := removeComments(string(b))
+	src, removed := removeCommentLines(string(b))
 
-	for _, s := range strings.SplitAfter(s, "}}") {
+	var off int
+	for _, s := range strings.SplitAfter(src, "}}") {
+		start := off
+		off += len(s)
 		op := strings.LastIndex(s, "{{")
 		if op == -1 {
 			continue
@@ -88,10 +102,11 @@ func parsePage(b []byte) *page {
 		}
 		switch name {
 		case "Card", "card":
-			c := populateCard(params)
-			c.BGColor = withDefaultColor(params["type"], c.BGColor)
-			c.ID = len(p.cards) + 1
-			p.cards = append(p.cards, c)
+			id := len(p.cards) + 1
+			line := removed.line(src, start+op)
+			p.cards = append(p.cards, newCard(params, id, rules))
+			p.lines = append(p.lines, line)
+			p.diagnostics = append(p.diagnostics, checkCard(params, id, line)...)
 		case "Subpage", "subpage":
 			sp, err := populateSubpage(params)
 			if err != nil {
@@ -122,45 +137,11 @@ func parseTemplate(s string) (name string, params map[string]string, err error)
 		return "", nil, errInvalid
 	}
 
-	var fields []string
-
-	switch {
-	case !strings.Contains(s, "[[") || !strings.Contains(s, "]]"):
-		fields = strings.Split(s, "|")
-	default:
-		for {
-			op := strings.Index(s, "[[")
-			if op == -1 {
-				break
-			}
-			cl := strings.Index(s[op:], "]]")
-			if cl == -1 {
-				break
-			}
-			switch {
-			case strings.HasPrefix(s[op+2:], "File:"), strings.HasPrefix(s[op+2:], "file:"):
-				name := parseLinkText(s[op : op+cl+2])
-				s = s[:op] + s[op+cl+2:]
-				if name != "" {
-					s += "|image=" + name
-				}
-			case strings.HasPrefix(s[op+2:], "User:"), strings.HasPrefix(s[op+2:], "user:"):
-				// If this is the first field in a wiki user signature,
-				// consume and ignore the rest.
-				post := s[op+cl+2:]
-				if strings.HasPrefix(strings.TrimSpace(post), "([[User talk:") {
-					post = post[strings.Index(post, "]]")+3:]
-					if stampEnd := strings.Index(post, " (UTC)"); stampEnd != -1 {
-						post = post[stampEnd+6:]
-					}
-				}
-				s = s[:op] + parseLinkText(s[op:op+cl+2]) + post
-			default:
-				s = s[:op] + parseLinkText(s[op:op+cl+2]) + s[op+cl+2:]
-			}
-		}
-		fields = strings.Split(s, "|")
+	s, images := replaceLinks(s)
+	for _, name := range images {
+		s += "|image=" + name
 	}
+	fields := strings.Split(s, "|")
 
 	name = strings.TrimSpace(fields[0])
 	if strings.HasPrefix(name, "Template:") ||
@@ -176,6 +157,46 @@ func parseTemplate(s string) (name string, params map[string]string, err error)
 	return
 }
 
+// replaceLinks replaces each internal link in s with its displayed text.
+// It removes links to image files and returns the names of the files.
+// A link to a user page that begins a signature replaces the whole
+// signature.
+func replaceLinks(s string) (string, []string) {
+	var images []string
+	for {
+		op := strings.Index(s, "[[")
+		if op == -1 {
+			break
+		}
+		cl := strings.Index(s[op:], "]]")
+		if cl == -1 {
+			break
+		}
+		switch {
+		case strings.HasPrefix(s[op+2:], "File:"), strings.HasPrefix(s[op+2:], "file:"):
+			name := parseLinkText(s[op : op+cl+2])
+			s = s[:op] + s[op+cl+2:]
+			if name != "" {
+				images = append(images, name)
+			}
+		case strings.HasPrefix(s[op+2:], "User:"), strings.HasPrefix(s[op+2:], "user:"):
+			// If this is the first field in a wiki user signature,
+			// consume and ignore the rest.
+			post := s[op+cl+2:]
+			if strings.HasPrefix(strings.TrimSpace(post), "([[User talk:") {
+				post = post[strings.Index(post, "]]")+3:]
+				if stampEnd := strings.Index(post, " (UTC)"); stampEnd != -1 {
+					post = post[stampEnd+6:]
+				}
+			}
+			s = s[:op] + parseLinkText(s[op:op+cl+2]) + post
+		default:
+			s = s[:op] + parseLinkText(s[op:op+cl+2]) + s[op+cl+2:]
+		}
+	}
+	return s, images
+}
+
 // parseParameter parses a named template parameter.
 // Whitespace is trimmed from the returned strings.
 // If s does not contain "=", name is the empty string.
@@ -222,6 +243,14 @@ func replacePair(s, old, new1, new2 string) string {
 // removeComments removes the spaces and one of the newlines as well.
 // https://github.com/wikimedia/mediawiki/blob/80d72fc07d509916224555c9a062892fc3690864/includes/parser/Sanitizer.php#L441
 func removeComments(s string) string {
+	s, _ = removeCommentLines(s)
+	return s
+}
+
+// removeCommentLines is like removeComments,
+// but also returns the newlines that it removes.
+func removeCommentLines(s string) (string, removedLines) {
+	var removed removedLines
 	for {
 		op := strings.Index(s, "<!--")
 		if op == -1 {
@@ -242,10 +271,37 @@ func removeComments(s string) string {
 		}
 		if lead > 0 && s[lead-1] == '\n' &&
 			trail < len(s) && s[trail] == '\n' {
+			removed = removed.add(lead, strings.Count(s[lead-1:trail+1], "\n")-1)
 			s = s[:lead-1] + "\n" + s[trail+1:]
 		} else {
+			removed = removed.add(op, strings.Count(s[op:cl], "\n"))
 			s = s[:op] + s[cl:]
 		}
 	}
-	return s
+	return s, removed
+}
+
+// removedLines records the newlines removed from a string.
+// Offsets are in increasing order.
+type removedLines []struct{ off, n int }
+
+// add records the removal of n newlines before offset off
+// of the resulting string.
+func (r removedLines) add(off, n int) removedLines {
+	if n == 0 {
+		return r
+	}
+	return append(r, struct{ off, n int }{off, n})
+}
+
+// line returns the line number in the original string
+// of the byte at offset off of s, the string with newlines removed.
+func (r removedLines) line(s string, off int) int {
+	n := 1 + strings.Count(s[:off], "\n")
+	for _, rl := range r {
+		if rl.off <= off {
+			n += rl.n
+		}
+	}
+	return n
 }
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/dkmccandless/dvorak/dvoraktest"
 	"kr.dev/diff"
 )
 
@@ -124,7 +125,7 @@ func TestParsePage(t *testing.T) {
 		{"", &page{}},
 		{"{{Subpage}}", &page{}},
 		{"{{card", &page{}},
-		{"{{card}}", &page{cards: []Card{{BGColor: otherGray, ID: 1}}}},
+		{"{{card}}", &page{cards: []Card{{BGColor: otherGray, ID: 1}}, lines: []int{1}}},
 		{
 			"{{Subpage|page=Cards 1-100}}",
 			&page{subpages: []subpage{{page: "Cards 1-100"}}},
@@ -143,6 +144,7 @@ func TestParsePage(t *testing.T) {
 					{Title: text("C"), Type: text("Letter"), BGColor: otherGray, ID: 2},
 					{Title: text("E"), BGColor: otherGray, ID: 3},
 				},
+				lines: []int{2, 4, 6},
 			},
 		},
 		{
@@ -164,11 +166,12 @@ func TestParsePage(t *testing.T) {
 					{Title: text("A"), Type: text("Action"), BGColor: "900", ID: 1},
 					{Title: text("B"), Type: text("Thing"), BGColor: "090", ID: 2},
 				},
+				lines: []int{5, 8},
 			},
 		},
 	} {
 		b := []byte(test.s)
-		p := parsePage(b)
+		p := parsePage(b, nil)
 		diff.Test(t, t.Errorf, p, test.p)
 	}
 }
@@ -237,3 +240,87 @@ func TestRemoveComments(t *testing.T) {
 		}
 	}
 }
+
+func TestDiagnose(t *testing.T) {
+	for _, tt := range []struct {
+		s     string
+		diags []Diagnostic
+	}{
+		{"", nil},
+		{"{{card|bgcolor=600|imgback=#FFD700}}", nil},
+		{
+			"{{card|title=A}}{{card|title=B|type=Thing|bgcolor=rgb(1,2)|imgback=fish}}",
+			[]Diagnostic{
+				{Kind: InvalidColor, Line: 1, Card: 2, Param: "bgcolor", Msg: `invalid color "rgb(1,2)"`},
+				{Kind: InvalidColor, Line: 1, Card: 2, Param: "imgback", Msg: `invalid color "fish"`},
+			},
+		},
+		{
+			"{{card|title=A}}\n<!--\n{{card}}\n-->\n{{card\n|title=<b>B\n|Title=C|colour=red|=D}}",
+			[]Diagnostic{
+				{Kind: UnknownParam, Line: 5, Card: 2, Param: "Title", Msg: "unknown parameter"},
+				{Kind: UnknownParam, Line: 5, Card: 2, Param: "colour", Msg: "unknown parameter"},
+				{Kind: UnbalancedHTML, Line: 5, Card: 2, Param: "title", Msg: "unclosed <b>"},
+			},
+		},
+	} {
+		diff.Test(t, t.Errorf, Diagnose([]byte(tt.s)), tt.diags)
+	}
+}
+
+func TestCardLines(t *testing.T) {
+	for _, tt := range []struct {
+		s     string
+		lines []int
+	}{
+		{"", nil},
+		{"{{card}}{{card}}\n{{card}}", []int{1, 1, 2}},
+		{"<!-- a\nb -->{{card}}\n\n{{card}}", []int{2, 4}},
+		{"a\n <!-- a\nb --> \n{{card}}<!--\n-->{{card}}\n{{card}}", []int{4, 5, 6}},
+	} {
+		diff.Test(t, t.Errorf, CardLines([]byte(tt.s)), tt.lines)
+	}
+}
+
+func TestGet(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	s.SetPage("Test Deck", "{{Subpage|page=Part 1}}{{Subpage|page=Part_2}}{{card|title=C}}")
+	s.SetPage("Test Deck/Part 1", "{{card|title=A}}")
+	s.SetPage("Test Deck/Part 2", "{{card|title=B}}")
+	s.SetPage("Test Deck/Part 2", "{{card|title=B2}}")
+	s.SetPage("Broken Deck", "{{Subpage|page=Missing}}")
+	c := &Client{HTTPClient: s.Client()}
+
+	for _, tt := range []struct {
+		url  string
+		want []string
+		ok   bool
+	}{
+		{"https://dvorakgame.co.uk/index.php/Test_Deck", []string{"A", "B2", "C"}, true},
+		{"http://dvorakgame.co.uk/index.php/Test_Deck/Part_1", []string{"A"}, true},
+		{"https://dvorakgame.co.uk/index.php?title=Test_Deck&action=edit", []string{"A", "B2", "C"}, true},
+		{"https://m.dvorakgame.co.uk/wiki/test_Deck#Cards", []string{"A", "B2", "C"}, true},
+		{"https://dvorakgame.co.uk/wiki/Test%20Deck%2FPart_1", []string{"A"}, true},
+		// Revisions 3 and 4 are of Part 2.
+		{"https://dvorakgame.co.uk/index.php?title=Test_Deck/Part_2&oldid=3", []string{"B"}, true},
+		{"https://dvorakgame.co.uk/index.php?oldid=4", []string{"B2"}, true},
+		{"https://dvorakgame.co.uk/index.php?title=Test_Deck&oldid=2", nil, false},
+		// Revision 1 predates the deck's subpages.
+		{"https://dvorakgame.co.uk/index.php?oldid=1", nil, false},
+		{"https://dvorakgame.co.uk/index.php/Missing", nil, false},
+		{"https://dvorakgame.co.uk/index.php/Broken_Deck", nil, false},
+		{"https://example.com/index.php/Test_Deck", nil, false},
+	} {
+		b, err := c.Get(tt.url)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("Get(%q): got error %v, want ok %v", tt.url, err, tt.ok)
+			continue
+		}
+		var titles []string
+		for _, card := range Parse(b) {
+			titles = append(titles, plainText(card.Title))
+		}
+		diff.Test(t, t.Errorf, titles, tt.want)
+	}
+}
@@ -0,0 +1,511 @@
+package dvoraktest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the GIF format
+	_ "image/jpeg" // register the JPEG format
+	_ "image/png"  // register the PNG format
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// invalidTitleChars are the characters that MediaWiki forbids in titles.
+const invalidTitleChars = "#<>[]{}"
+
+// namespaces maps the names of the namespaces that the fake wiki knows
+// to their numbers.
+var namespaces = map[string]int{
+	"":         0,
+	"User":     2,
+	"File":     6,
+	"Template": 10,
+	"Category": 14,
+}
+
+// namespace returns the number of the namespace of the normalized title.
+func namespace(title string) int {
+	if i := strings.Index(title, ":"); i >= 0 {
+		if ns, ok := namespaces[title[:i]]; ok {
+			return ns
+		}
+	}
+	return 0
+}
+
+// object is a JSON object of an api.php result.
+type object = map[string]interface{}
+
+// serveAPI serves an api.php query in JSON format version 2.
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		apiError(w, "badrequest", err.Error())
+		return
+	}
+	q := r.Form
+	if q.Get("action") != "query" {
+		apiError(w, "badvalue", fmt.Sprintf("Unrecognized value for parameter \"action\": %s.", q.Get("action")))
+		return
+	}
+	if q.Get("format") != "json" || q.Get("formatversion") != "2" {
+		apiError(w, "badvalue", "Only format=json and formatversion=2 are supported.")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := make(object)
+	cont := make(map[string]string)
+	if q.Get("titles") != "" || q.Get("revids") != "" {
+		if code, info := s.queryPages(q, query, cont, "http://"+r.Host); code != "" {
+			apiError(w, code, info)
+			return
+		}
+	}
+	if list := q.Get("list"); list != "" {
+		if code, info := s.queryList(list, q, query, cont); code != "" {
+			apiError(w, code, info)
+			return
+		}
+	}
+
+	result := object{"batchcomplete": true, "query": query}
+	if len(cont) > 0 {
+		cont["continue"] = "-||"
+		result["continue"] = cont
+	}
+	writeJSON(w, result)
+}
+
+// apiError writes an api.php error response with the given code and info.
+func apiError(w http.ResponseWriter, code, info string) {
+	w.Header().Set("MediaWiki-API-Error", code)
+	writeJSON(w, object{"error": object{"code": code, "info": info}})
+}
+
+// writeJSON writes v to w as a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(v)
+}
+
+// queryPages adds the pages named by the titles or revids parameter of q
+// to query, with the properties given by the prop parameter.
+// It adds any continuation parameters to cont. It returns the code and
+// info of an error, if any. base is the URL of the server.
+// s.mu must be held.
+func (s *Server) queryPages(q url.Values, query object, cont map[string]string, base string) (code, info string) {
+	props := splitParam(q.Get("prop"))
+	for _, p := range props {
+		if p != "revisions" && p != "imageinfo" {
+			return "badvalue", fmt.Sprintf("Unrecognized value for parameter \"prop\": %s.", p)
+		}
+	}
+
+	// revs lists the revisions requested by revids, keyed by title.
+	var revs map[string][]Revision
+	var titles []string
+	if q.Get("revids") != "" {
+		revs = make(map[string][]Revision)
+		for _, id := range splitParam(q.Get("revids")) {
+			title, r, ok := s.revision(id)
+			if !ok {
+				query["badrevids"] = append(asSlice(query["badrevids"]), object{"revid": id, "missing": true})
+				continue
+			}
+			if revs[title] == nil {
+				titles = append(titles, title)
+			}
+			revs[title] = append(revs[title], r)
+		}
+	} else {
+		var normalized, redirects []object
+		for _, t := range splitParam(q.Get("titles")) {
+			title := normalize(t)
+			if title != t {
+				normalized = append(normalized, object{"fromencoded": false, "from": t, "to": title})
+			}
+			if to, ok := s.redirects[title]; ok && q.Get("redirects") != "" {
+				redirects = append(redirects, object{"from": title, "to": to})
+				title = to
+			}
+			if !containsString(titles, title) {
+				titles = append(titles, title)
+			}
+		}
+		if normalized != nil {
+			query["normalized"] = normalized
+		}
+		if redirects != nil {
+			query["redirects"] = redirects
+		}
+	}
+
+	if len(titles) > 1 && q.Get("rvlimit") != "" && containsString(props, "revisions") {
+		return "multpages", "rvlimit may only be used with a single page."
+	}
+	var pages []object
+	for _, title := range titles {
+		p := object{"ns": namespace(title), "title": title}
+		pages = append(pages, p)
+		if strings.ContainsAny(title, invalidTitleChars) || title == "" {
+			p["invalid"] = true
+			p["invalidreason"] = "The requested page title contains invalid characters."
+			continue
+		}
+		history := s.pages[title]
+		f, isFile := s.files[strings.TrimPrefix(title, "File:")]
+		isFile = isFile && namespace(title) == namespaces["File"]
+		if len(history) == 0 && !isFile {
+			p["missing"] = true
+			continue
+		}
+		for _, prop := range props {
+			switch prop {
+			case "revisions":
+				rs := revs[title]
+				if revs == nil {
+					var next *Revision
+					rs, next = selectRevisions(history, q, s.maxLimit())
+					if next != nil {
+						cont["rvcontinue"] = strconv.FormatInt(next.ID, 10)
+					}
+				}
+				p["revisions"] = revisionObjects(rs, q)
+			case "imageinfo":
+				if isFile {
+					p["imageinfo"] = []object{imageInfo(base, strings.TrimPrefix(title, "File:"), f, q)}
+				}
+			}
+		}
+	}
+	query["pages"] = pages
+	return "", ""
+}
+
+// revision returns the revision with the given ID and the title of its page.
+// s.mu must be held.
+func (s *Server) revision(id string) (string, Revision, bool) {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return "", Revision{}, false
+	}
+	for _, title := range s.titles() {
+		for _, r := range s.pages[title] {
+			if r.ID == n {
+				return title, r, true
+			}
+		}
+	}
+	return "", Revision{}, false
+}
+
+// selectRevisions returns the revisions of history selected by the rvlimit,
+// rvdir, rvstart, and rvcontinue parameters of q, and the first revision
+// that was not returned because of rvlimit, if any. Without rvlimit, only
+// the latest revision is selected. An rvlimit of "max" stands for max.
+func selectRevisions(history []Revision, q url.Values, max int) (rs []Revision, next *Revision) {
+	if q.Get("rvlimit") == "" {
+		return history[len(history)-1:], nil
+	}
+	newer := q.Get("rvdir") == "newer"
+	var start time.Time
+	if v := q.Get("rvstart"); v != "" {
+		start, _ = time.Parse(time.RFC3339, v)
+	}
+	var ordered []Revision
+	for i := range history {
+		r := history[i]
+		if !newer {
+			r = history[len(history)-1-i]
+		}
+		switch {
+		case start.IsZero():
+		case newer && r.Timestamp.Before(start), !newer && r.Timestamp.After(start):
+			continue
+		}
+		ordered = append(ordered, r)
+	}
+	if v := q.Get("rvcontinue"); v != "" {
+		for i, r := range ordered {
+			if strconv.FormatInt(r.ID, 10) == v {
+				ordered = ordered[i:]
+				break
+			}
+		}
+	}
+	if limit, ok := parseLimit(q.Get("rvlimit"), max); ok && limit < len(ordered) {
+		return ordered[:limit], &ordered[limit]
+	}
+	return ordered, nil
+}
+
+// revisionObjects returns rs with the properties given by
+// the rvprop and rvslots parameters of q.
+func revisionObjects(rs []Revision, q url.Values) []object {
+	rvprop := splitParam(q.Get("rvprop"))
+	if q.Get("rvprop") == "" {
+		rvprop = []string{"ids", "timestamp", "flags", "comment", "user"}
+	}
+	objs := make([]object, len(rs))
+	for i, r := range rs {
+		o := make(object)
+		for _, prop := range rvprop {
+			switch prop {
+			case "ids":
+				o["revid"] = r.ID
+			case "timestamp":
+				o["timestamp"] = r.Timestamp.UTC().Format(time.RFC3339)
+			case "user":
+				o["user"] = r.User
+			case "comment":
+				o["comment"] = r.Comment
+			case "content":
+				if q.Get("rvslots") == "" {
+					o["content"] = r.Content
+					break
+				}
+				o["slots"] = object{"main": object{
+					"contentmodel":  "wikitext",
+					"contentformat": "text/x-wiki",
+					"content":       r.Content,
+				}}
+			}
+		}
+		objs[i] = o
+	}
+	return objs
+}
+
+// imageInfo returns the properties of the file f with the given name
+// given by the iiprop, iiurlwidth, iiurlheight, and iiextmetadatafilter
+// parameters of q. base is the URL of the server.
+func imageInfo(base, name string, f File, q url.Values) object {
+	cfg, _, _ := image.DecodeConfig(bytes.NewReader(f.Content))
+	o := make(object)
+	for _, prop := range splitParam(q.Get("iiprop")) {
+		switch prop {
+		case "url":
+			fileURL := base + filePath(name)
+			o["url"] = fileURL
+			o["descriptionurl"] = base + "/index.php/File:" + url.PathEscape(strings.ReplaceAll(name, " ", "_"))
+			w, _ := strconv.Atoi(q.Get("iiurlwidth"))
+			h, _ := strconv.Atoi(q.Get("iiurlheight"))
+			if w <= 0 && h <= 0 {
+				break
+			}
+			tw, th := thumbSize(cfg.Width, cfg.Height, w, h)
+			o["thumburl"] = fileURL
+			if tw < cfg.Width {
+				o["thumburl"] = base + thumbPath(name, tw)
+			}
+			o["thumbwidth"], o["thumbheight"] = tw, th
+		case "sha1":
+			sum := sha1.Sum(f.Content)
+			o["sha1"] = hex.EncodeToString(sum[:])
+			if f.SHA1 != "" {
+				o["sha1"] = f.SHA1
+			}
+		case "size":
+			o["size"] = len(f.Content)
+			o["width"], o["height"] = cfg.Width, cfg.Height
+		case "mime":
+			o["mime"] = http.DetectContentType(f.Content)
+		case "user":
+			o["user"] = f.User
+		case "extmetadata":
+			meta := make(object)
+			filter := splitParam(q.Get("iiextmetadatafilter"))
+			for k, v := range f.Metadata {
+				if len(filter) == 0 || containsString(filter, k) {
+					meta[k] = object{"value": v, "source": "commons-desc-page"}
+				}
+			}
+			o["extmetadata"] = meta
+		}
+	}
+	return o
+}
+
+// thumbSize returns the size of a thumbnail of a width by height image that
+// fits within maxWidth and maxHeight, either of which may be 0 to leave it
+// unconstrained. Images are not scaled up.
+func thumbSize(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return width, height
+	}
+	scale := 1.0
+	if maxWidth > 0 {
+		scale = math.Min(scale, float64(maxWidth)/float64(width))
+	}
+	if maxHeight > 0 {
+		scale = math.Min(scale, float64(maxHeight)/float64(height))
+	}
+	return int(math.Round(float64(width) * scale)), int(math.Round(float64(height) * scale))
+}
+
+// filePath returns the path of the file with the given name,
+// which MediaWiki places in directories named by its MD5 hash.
+func filePath(name string) string {
+	name = strings.ReplaceAll(name, " ", "_")
+	sum := md5.Sum([]byte(name))
+	h := hex.EncodeToString(sum[:])
+	return "/images/" + h[:1] + "/" + h[:2] + "/" + url.PathEscape(name)
+}
+
+// thumbPath returns the path of a thumbnail of the file with
+// the given name that is width pixels wide.
+func thumbPath(name string, width int) string {
+	p := filePath(name)
+	base := p[strings.LastIndex(p, "/")+1:]
+	return "/images/thumb/" + strings.TrimPrefix(p, "/images/") + "/" + strconv.Itoa(width) + "px-" + base
+}
+
+// queryList adds the titles of the pages in the named list to query.
+// It adds any continuation parameters to cont. It returns the code and
+// info of an error, if any.
+// s.mu must be held.
+func (s *Server) queryList(list string, q url.Values, query object, cont map[string]string) (code, info string) {
+	var prefix string
+	var match func(content string) bool
+	switch list {
+	case "categorymembers":
+		prefix = "cm"
+		cat := normalize(q.Get("cmtitle"))
+		if namespace(cat) != namespaces["Category"] {
+			return "invalidcategory", "The category name you entered is not valid."
+		}
+		match = linkMatcher(`\[\[\s*[Cc]ategory\s*:\s*`, strings.TrimPrefix(cat, "Category:"), `\s*[|\]]`)
+	case "embeddedin":
+		prefix = "ei"
+		tmpl := normalize(q.Get("eititle"))
+		name := tmpl
+		if namespace(tmpl) == namespaces["Template"] {
+			name = strings.TrimPrefix(tmpl, "Template:")
+		}
+		match = linkMatcher(`\{\{\s*`, name, `\s*[|}]`)
+	default:
+		return "badvalue", fmt.Sprintf("Unrecognized value for parameter \"list\": %s.", list)
+	}
+
+	var ns []int
+	for _, v := range splitParam(q.Get(prefix + "namespace")) {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "badvalue", fmt.Sprintf("Invalid value %q for parameter \"%snamespace\".", v, prefix)
+		}
+		ns = append(ns, n)
+	}
+
+	var members []object
+	for _, title := range s.titles() {
+		if ns != nil && !containsInt(ns, namespace(title)) {
+			continue
+		}
+		history := s.pages[title]
+		if match(history[len(history)-1].Content) {
+			members = append(members, object{"ns": namespace(title), "title": title})
+		}
+	}
+
+	offset := 0
+	if v := q.Get(prefix + "continue"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i > len(members) {
+			return "badcontinue", "Invalid continue param."
+		}
+		offset = i
+		members = members[i:]
+	}
+	if limit, ok := parseLimit(q.Get(prefix+"limit"), s.maxLimit()); ok && limit < len(members) {
+		members = members[:limit]
+		cont[prefix+"continue"] = strconv.Itoa(offset + limit)
+	}
+	if members == nil {
+		members = []object{}
+	}
+	query[list] = members
+	return "", ""
+}
+
+// parseLimit parses the value of a limit parameter, which is a number
+// or "max", which stands for max.
+func parseLimit(v string, max int) (int, bool) {
+	if v == "max" {
+		return max, true
+	}
+	n, err := strconv.Atoi(v)
+	return n, err == nil
+}
+
+// maxLimit returns the limit that "max" stands for.
+func (s *Server) maxLimit() int {
+	if s.MaxLimit > 0 {
+		return s.MaxLimit
+	}
+	return 500
+}
+
+// linkMatcher returns a function that reports whether content contains
+// a link or transclusion of the page with the given name, between
+// the regular expressions open and close. The first letter of the name
+// is case-insensitive, and spaces and underscores are interchangeable.
+func linkMatcher(open, name, close string) func(string) bool {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case i == 0:
+			b.WriteString("(?:" + regexp.QuoteMeta(strings.ToUpper(string(r))) + "|" + regexp.QuoteMeta(strings.ToLower(string(r))) + ")")
+		case r == ' ':
+			b.WriteString("[ _]+")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	re := regexp.MustCompile(open + b.String() + close)
+	return re.MatchString
+}
+
+// splitParam returns the values of a multi-value api.php parameter.
+func splitParam(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, "|")
+}
+
+// asSlice returns v as a slice of objects, or nil if it is not one.
+func asSlice(v interface{}) []object {
+	s, _ := v.([]object)
+	return s
+}
+
+// containsString reports whether ss contains s.
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt reports whether ns contains n.
+func containsInt(ns []int, n int) bool {
+	for _, v := range ns {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
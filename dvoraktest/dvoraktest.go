@@ -0,0 +1,314 @@
+// Package dvoraktest provides a fake Dvorak wiki for hermetic tests.
+//
+// A Server is an httptest.Server that implements the parts of MediaWiki
+// that package dvorak uses: raw pages from index.php, api.php queries of
+// revisions, image information, category members, and template
+// transclusions, and the image files themselves. Its pages and files are
+// held in memory and may be loaded from a directory.
+//
+// The http.Client returned by Server.Client sends requests for any host to
+// the Server, so code under test can use the real wiki's URLs:
+//
+//	s := dvoraktest.NewServer()
+//	defer s.Close()
+//	s.SetPage("Test Deck", "{{card|title=A}}")
+//	c := &dvorak.Client{HTTPClient: s.Client()}
+//	b, err := c.Get("https://dvorakgame.co.uk/index.php/Test_Deck")
+package dvoraktest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Revision is a version of a page.
+type Revision struct {
+	// ID is the revision ID. If 0, SetPage and AddRevision assign
+	// the next unused ID.
+	ID int64
+
+	// Timestamp is the time at which the revision was saved.
+	// If zero, SetPage and AddRevision assign a time one minute after
+	// the latest revision of any page, or Epoch if there is none.
+	Timestamp time.Time
+
+	User    string
+	Comment string
+	Content string
+}
+
+// Epoch is the time of the first revision assigned a default Timestamp.
+var Epoch = time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// A File is an uploaded file.
+type File struct {
+	Content []byte
+
+	// User is the name of the uploader.
+	User string
+
+	// Metadata holds the file's extended metadata, such as "Artist"
+	// and "LicenseShortName", which may contain HTML.
+	Metadata map[string]string
+
+	// SHA1, if not empty, is the hash reported for the file instead of
+	// the SHA-1 hash of Content, as if the file were corrupted.
+	SHA1 string
+}
+
+// A Server is a fake Dvorak wiki. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// MaxLimit is the number of results that a limit parameter of
+	// "max", such as rvlimit=max, stands for. If 0, it is 500,
+	// MediaWiki's limit for clients without the apihighlimits right.
+	MaxLimit int
+
+	mu        sync.Mutex
+	pages     map[string][]Revision // by title, oldest first
+	redirects map[string]string
+	files     map[string]File
+	lastID    int64
+	lastTime  time.Time
+	requests  int
+}
+
+// NewServer starts and returns a new Server with no pages or files.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		pages:     make(map[string][]Revision),
+		redirects: make(map[string]string),
+		files:     make(map[string]File),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an HTTP client that sends requests for any URL to s.
+func (s *Server) Client() *http.Client {
+	addr := s.Listener.Addr().String()
+	dial := func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: dial,
+			// Requests for https URLs are sent to s without TLS.
+			DialTLSContext: dial,
+		},
+	}
+}
+
+// Requests returns the number of requests that s has served.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// SetPage adds a revision with the given content to the page with
+// the given title, creating it if necessary.
+func (s *Server) SetPage(title, content string) {
+	s.AddRevision(title, Revision{Content: content})
+}
+
+// AddRevision adds r as the latest revision of the page with the given
+// title, creating it if necessary.
+func (s *Server) AddRevision(title string, r Revision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.ID == 0 {
+		r.ID = s.lastID + 1
+	}
+	if r.ID > s.lastID {
+		s.lastID = r.ID
+	}
+	if r.Timestamp.IsZero() {
+		r.Timestamp = Epoch
+		if !s.lastTime.IsZero() {
+			r.Timestamp = s.lastTime.Add(time.Minute)
+		}
+	}
+	if r.Timestamp.After(s.lastTime) {
+		s.lastTime = r.Timestamp
+	}
+	title = normalize(title)
+	s.pages[title] = append(s.pages[title], r)
+}
+
+// SetRedirect makes the page with the title from redirect to the page
+// with the title to. Redirects of file names apply to files.
+func (s *Server) SetRedirect(from, to string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.redirects[normalize(from)] = normalize(to)
+}
+
+// SetFile uploads f with the given name, without the "File:" prefix.
+func (s *Server) SetFile(name string, f File) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[normalize(name)] = f
+}
+
+// LoadDir loads pages and files from the directory dir.
+// Each file in dir/pages with the extension ".wiki" is the content of
+// the page whose title is its path relative to dir/pages without the
+// extension, with underscores instead of spaces, so that
+// dir/pages/Cthulhu_Deck/Part_1.wiki is the page "Cthulhu Deck/Part 1".
+// Each file in dir/images is an uploaded file with the same name.
+// Either subdirectory may be absent.
+func (s *Server) LoadDir(dir string) error {
+	pages := filepath.Join(dir, "pages")
+	err := filepath.Walk(pages, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() || filepath.Ext(path) != ".wiki" {
+			return err
+		}
+		rel, err := filepath.Rel(pages, path)
+		if err != nil {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		s.SetPage(strings.TrimSuffix(filepath.ToSlash(rel), ".wiki"), string(b))
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	images, err := os.ReadDir(filepath.Join(dir, "images"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, e := range images {
+		if e.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, "images", e.Name()))
+		if err != nil {
+			return err
+		}
+		s.SetFile(e.Name(), File{Content: b})
+	}
+	return nil
+}
+
+// normalize returns title in MediaWiki normalized form,
+// with spaces instead of underscores and an initial capital.
+func normalize(title string) string {
+	title = strings.TrimSpace(strings.ReplaceAll(title, "_", " "))
+	if i := strings.Index(title, ":"); i >= 0 && knownNamespace(title[:i]) {
+		ns := capitalize(strings.ToLower(title[:i]))
+		if ns == "Image" {
+			ns = "File"
+		}
+		return ns + ":" + capitalize(strings.TrimSpace(title[i+1:]))
+	}
+	return capitalize(title)
+}
+
+// knownNamespace reports whether ns is the name of a namespace
+// that the fake wiki knows.
+func knownNamespace(ns string) bool {
+	switch strings.ToLower(ns) {
+	case "file", "image", "category", "template", "user":
+		return true
+	}
+	return false
+}
+
+// capitalize returns s with its first letter in upper case.
+func capitalize(s string) string {
+	for i, r := range s {
+		return strings.ToUpper(string(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+// serveHTTP serves index.php, api.php, and image files.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+
+	switch p := r.URL.Path; {
+	case p == "/api.php":
+		s.serveAPI(w, r)
+	case p == "/index.php" || strings.HasPrefix(p, "/index.php/"):
+		s.serveIndex(w, r)
+	case strings.HasPrefix(p, "/images/"):
+		s.serveFile(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveIndex serves the raw content of a page.
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("action") != "raw" {
+		http.Error(w, "only action=raw is supported", http.StatusNotImplemented)
+		return
+	}
+	title := r.FormValue("title")
+	if title == "" {
+		title = strings.TrimPrefix(r.URL.Path, "/index.php/")
+	}
+	s.mu.Lock()
+	revs := s.pages[normalize(title)]
+	s.mu.Unlock()
+	if len(revs) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/x-wiki; charset=UTF-8")
+	w.Write([]byte(revs[len(revs)-1].Content))
+}
+
+// serveFile serves an image file or a thumbnail, which is the original file.
+// Files are at /images/a/ab/Name and thumbnails are at
+// /images/thumb/a/ab/Name/100px-Name.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/images/"), "/")
+	if parts[0] == "thumb" {
+		parts = parts[1:]
+	}
+	if len(parts) < 3 {
+		http.NotFound(w, r)
+		return
+	}
+	name := parts[2]
+	s.mu.Lock()
+	f, ok := s.files[normalize(name)]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", http.DetectContentType(f.Content))
+	w.Write(f.Content)
+}
+
+// titles returns the titles of the pages of s in order.
+// s.mu must be held.
+func (s *Server) titles() []string {
+	titles := make([]string, 0, len(s.pages))
+	for t := range s.pages {
+		titles = append(titles, t)
+	}
+	sort.Strings(titles)
+	return titles
+}
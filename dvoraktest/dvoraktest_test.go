@@ -0,0 +1,320 @@
+package dvoraktest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kr.dev/diff"
+)
+
+// get returns the body of the response to a GET request for rawURL
+// sent by the Client of s, and its status code.
+func get(t *testing.T, s *Server, rawURL string) (string, int) {
+	t.Helper()
+	r, err := s.Client().Get(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Body.Close()
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b), r.StatusCode
+}
+
+// query returns the decoded result of an api.php query with params.
+func query(t *testing.T, s *Server, params url.Values) map[string]interface{} {
+	t.Helper()
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+	body, status := get(t, s, "https://dvorakgame.co.uk/api.php?"+params.Encode())
+	if status != http.StatusOK {
+		t.Fatalf("status %v", status)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// pngImage returns a width by height PNG image.
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRaw(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetPage("Test Deck", "old")
+	s.SetPage("Test_Deck", "new")
+	s.SetPage("Test Deck/Part 1", "part")
+
+	for _, tt := range []struct {
+		url    string
+		body   string
+		status int
+	}{
+		{"https://dvorakgame.co.uk/index.php/Test_Deck?action=raw", "new", 200},
+		{"http://www.dvorakgame.co.uk/index.php/test_Deck?action=raw", "new", 200},
+		{"https://dvorakgame.co.uk/index.php?title=Test_Deck&action=raw", "new", 200},
+		{"https://dvorakgame.co.uk/index.php/Test_Deck/Part%201?action=raw", "part", 200},
+		{"https://dvorakgame.co.uk/index.php/Missing?action=raw", "", 404},
+		{"https://dvorakgame.co.uk/index.php/Test_Deck", "", 501},
+	} {
+		body, status := get(t, s, tt.url)
+		if status != tt.status || (status == 200 && body != tt.body) {
+			t.Errorf("%v: got %v %q, want %v %q", tt.url, status, body, tt.status, tt.body)
+		}
+	}
+	if n := s.Requests(); n != 6 {
+		t.Errorf("Requests() = %v, want 6", n)
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddRevision("Test Deck", Revision{User: "A", Comment: "create", Content: "one"})
+	s.AddRevision("Test Deck", Revision{User: "B", Comment: "edit", Content: "two"})
+	s.AddRevision("Test Deck", Revision{ID: 10, Timestamp: Epoch.Add(time.Hour), User: "C", Content: "three"})
+	s.SetPage("Other", "other")
+	s.SetRedirect("Old Deck", "Test Deck")
+
+	t.Run("history", func(t *testing.T) {
+		got := query(t, s, url.Values{
+			"action": {"query"}, "prop": {"revisions"}, "titles": {"Test_Deck"},
+			"rvprop": {"ids|user|comment"}, "rvlimit": {"2"},
+		})
+		want := map[string]interface{}{
+			"batchcomplete": true,
+			"continue":      map[string]interface{}{"rvcontinue": "1", "continue": "-||"},
+			"query": map[string]interface{}{
+				"normalized": []interface{}{
+					map[string]interface{}{"fromencoded": false, "from": "Test_Deck", "to": "Test Deck"},
+				},
+				"pages": []interface{}{map[string]interface{}{
+					"ns": 0.0, "title": "Test Deck",
+					"revisions": []interface{}{
+						map[string]interface{}{"revid": 10.0, "user": "C", "comment": ""},
+						map[string]interface{}{"revid": 2.0, "user": "B", "comment": "edit"},
+					},
+				}},
+			},
+		}
+		diff.Test(t, t.Errorf, got, want)
+	})
+
+	t.Run("as of", func(t *testing.T) {
+		got := query(t, s, url.Values{
+			"action": {"query"}, "prop": {"revisions"}, "titles": {"Old Deck"}, "redirects": {"1"},
+			"rvprop": {"content"}, "rvslots": {"main"}, "rvlimit": {"1"}, "rvdir": {"older"},
+			"rvstart": {Epoch.Add(30 * time.Second).Format(time.RFC3339)},
+		})
+		q := got["query"].(map[string]interface{})
+		diff.Test(t, t.Errorf, q["redirects"], []interface{}{
+			map[string]interface{}{"from": "Old Deck", "to": "Test Deck"},
+		})
+		rev := q["pages"].([]interface{})[0].(map[string]interface{})["revisions"].([]interface{})[0]
+		content := rev.(map[string]interface{})["slots"].(map[string]interface{})["main"].(map[string]interface{})["content"]
+		if content != "one" {
+			t.Errorf("content = %q, want %q", content, "one")
+		}
+	})
+
+	t.Run("latest", func(t *testing.T) {
+		got := query(t, s, url.Values{
+			"action": {"query"}, "prop": {"revisions"}, "titles": {"Test Deck|Other|Missing|A<B"},
+			"rvprop": {"content"},
+		})
+		diff.Test(t, t.Errorf, got["query"], map[string]interface{}{
+			"pages": []interface{}{
+				map[string]interface{}{"ns": 0.0, "title": "Test Deck", "revisions": []interface{}{
+					map[string]interface{}{"content": "three"},
+				}},
+				map[string]interface{}{"ns": 0.0, "title": "Other", "revisions": []interface{}{
+					map[string]interface{}{"content": "other"},
+				}},
+				map[string]interface{}{"ns": 0.0, "title": "Missing", "missing": true},
+				map[string]interface{}{"ns": 0.0, "title": "A<B", "invalid": true,
+					"invalidreason": "The requested page title contains invalid characters."},
+			},
+		})
+	})
+
+	t.Run("revids", func(t *testing.T) {
+		got := query(t, s, url.Values{
+			"action": {"query"}, "prop": {"revisions"}, "revids": {"2|99"}, "rvprop": {"ids|content"},
+		})
+		diff.Test(t, t.Errorf, got["query"], map[string]interface{}{
+			"badrevids": []interface{}{map[string]interface{}{"revid": "99", "missing": true}},
+			"pages": []interface{}{
+				map[string]interface{}{"ns": 0.0, "title": "Test Deck", "revisions": []interface{}{
+					map[string]interface{}{"revid": 2.0, "content": "two"},
+				}},
+			},
+		})
+	})
+}
+
+func TestImageInfo(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	img := pngImage(t, 400, 200)
+	s.SetFile("Some art.png", File{
+		Content:  img,
+		User:     "Uploader",
+		Metadata: map[string]string{"Artist": "<b>Artist</b>", "Other": "x"},
+	})
+	s.SetRedirect("File:Old art.png", "File:Some art.png")
+	s.SetFile("Corrupt.png", File{Content: img, SHA1: "0123456789abcdef0123456789abcdef01234567"})
+
+	got := query(t, s, url.Values{
+		"action": {"query"}, "prop": {"imageinfo"}, "redirects": {"1"},
+		"titles":              {"File:Some_art.png|File:Old art.png|File:Missing.png"},
+		"iiprop":              {"url|sha1|size|mime|user|extmetadata"},
+		"iiurlwidth":          {"100"},
+		"iiextmetadatafilter": {"Artist"},
+	})
+	pages := got["query"].(map[string]interface{})["pages"].([]interface{})
+	if len(pages) != 2 {
+		t.Fatalf("got %d pages, want 2", len(pages))
+	}
+	diff.Test(t, t.Errorf, pages[1], map[string]interface{}{"ns": 6.0, "title": "File:Missing.png", "missing": true})
+
+	ii := pages[0].(map[string]interface{})["imageinfo"].([]interface{})[0].(map[string]interface{})
+	sum := sha1.Sum(img)
+	for k, want := range map[string]interface{}{
+		"sha1":        hex.EncodeToString(sum[:]),
+		"width":       400.0,
+		"height":      200.0,
+		"mime":        "image/png",
+		"user":        "Uploader",
+		"thumbwidth":  100.0,
+		"thumbheight": 50.0,
+		"extmetadata": map[string]interface{}{
+			"Artist": map[string]interface{}{"value": "<b>Artist</b>", "source": "commons-desc-page"},
+		},
+	} {
+		diff.Test(t, t.Errorf, ii[k], want)
+	}
+
+	got = query(t, s, url.Values{
+		"action": {"query"}, "prop": {"imageinfo"}, "titles": {"File:Corrupt.png"}, "iiprop": {"sha1"},
+	})
+	pages = got["query"].(map[string]interface{})["pages"].([]interface{})
+	ii2 := pages[0].(map[string]interface{})["imageinfo"].([]interface{})[0].(map[string]interface{})
+	diff.Test(t, t.Errorf, ii2["sha1"], "0123456789abcdef0123456789abcdef01234567")
+
+	for _, key := range []string{"url", "thumburl"} {
+		u := ii[key].(string)
+		if !strings.Contains(u, "Some_art.png") {
+			t.Errorf("%s = %q, want the file's name", key, u)
+		}
+		body, status := get(t, s, u)
+		if status != http.StatusOK || body != string(img) {
+			t.Errorf("%s: got status %v and %d bytes, want the image", key, status, len(body))
+		}
+	}
+}
+
+func TestLists(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.SetPage("B Deck", "[[Category:Decks]] {{card|title=B}}")
+	s.SetPage("A Deck", "[[category:decks|A]]")
+	s.SetPage("A Deck/Part 1", "{{ Card |title=A}}")
+	s.SetPage("Other", "{{cardinal}} [[Category:Decks in progress]]")
+	s.SetPage("Template:Card", "[[Category:Decks]]")
+
+	titles := func(v map[string]interface{}, list string) []string {
+		var ts []string
+		for _, p := range v["query"].(map[string]interface{})[list].([]interface{}) {
+			ts = append(ts, p.(map[string]interface{})["title"].(string))
+		}
+		return ts
+	}
+
+	got := query(t, s, url.Values{
+		"action": {"query"}, "list": {"categorymembers"}, "cmtitle": {"Category:Decks"}, "cmnamespace": {"0"},
+	})
+	diff.Test(t, t.Errorf, titles(got, "categorymembers"), []string{"A Deck", "B Deck"})
+
+	params := url.Values{
+		"action": {"query"}, "list": {"embeddedin"}, "eititle": {"Template:Card"}, "eilimit": {"1"},
+	}
+	var all []string
+	for i := 0; i < 3; i++ {
+		got := query(t, s, params)
+		all = append(all, titles(got, "embeddedin")...)
+		cont, ok := got["continue"].(map[string]interface{})
+		if !ok {
+			break
+		}
+		params.Set("eicontinue", cont["eicontinue"].(string))
+	}
+	diff.Test(t, t.Errorf, all, []string{"A Deck/Part 1", "B Deck"})
+}
+
+func TestAPIError(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	got := query(t, s, url.Values{"action": {"parse"}})
+	if e, ok := got["error"].(map[string]interface{}); !ok || e["code"] != "badvalue" {
+		t.Errorf("got %v, want a badvalue error", got)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	img := pngImage(t, 1, 1)
+	for name, content := range map[string][]byte{
+		"pages/Test_Deck.wiki":        []byte("main"),
+		"pages/Test_Deck/Part_1.wiki": []byte("part"),
+		"pages/README":                []byte("ignored"),
+		"images/Art.png":              img,
+	} {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := NewServer()
+	defer s.Close()
+	if err := s.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	diff.Test(t, t.Errorf, s.titles(), []string{"Test Deck", "Test Deck/Part 1"})
+	if body, _ := get(t, s, "https://dvorakgame.co.uk/index.php/Test_Deck/Part_1?action=raw"); body != "part" {
+		t.Errorf("subpage content = %q, want %q", body, "part")
+	}
+	if body, _ := get(t, s, "https://dvorakgame.co.uk"+filePath("Art.png")); body != string(img) {
+		t.Errorf("got %d bytes of image, want %d", len(body), len(img))
+	}
+
+	if err := s.LoadDir(filepath.Join(dir, "nonexistent")); err != nil {
+		t.Errorf("LoadDir(nonexistent) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,71 @@
+package dvorak
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrInvalidHost is the error wrapped by the errors of functions given
+// the URL of a page that is not on a host of the Client's Site.
+var ErrInvalidHost = errors.New("invalid host")
+
+// A PageNotFoundError reports that a wiki page does not exist.
+type PageNotFoundError struct {
+	Title string
+}
+
+func (e *PageNotFoundError) Error() string {
+	return fmt.Sprintf("%v: page does not exist", e.Title)
+}
+
+// An HTTPStatusError reports that the wiki responded to a request
+// with a status other than 200 OK.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%v: status %v", e.URL, e.StatusCode)
+}
+
+// An APIError is an error reported by the MediaWiki API.
+// https://www.mediawiki.org/wiki/API:Errors_and_warnings
+type APIError struct {
+	// Code is the error code, such as "maxlag" or "badvalue".
+	Code string
+
+	// Info is a description of the error.
+	Info string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api.php: %s: %s", e.Code, e.Info)
+}
+
+// A SubpageError reports that a subpage of a deck could not be read.
+// A SubpageError whose Err is a *PageNotFoundError reports a missing
+// subpage rather than a missing deck.
+type SubpageError struct {
+	// Title is the title of the subpage.
+	Title string
+
+	Err error
+}
+
+func (e *SubpageError) Error() string {
+	return fmt.Sprintf("subpage %v: %v", e.Title, e.Err)
+}
+
+func (e *SubpageError) Unwrap() error { return e.Err }
+
+// pageError returns err, or a *PageNotFoundError for the page with
+// the given title if err reports that the page does not exist.
+func pageError(err error, title string) error {
+	var se *HTTPStatusError
+	if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+		return &PageNotFoundError{Title: title}
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package dvorak
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dkmccandless/dvorak/dvoraktest"
+)
+
+func TestErrors(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	s.SetPage("Test Deck", "{{Subpage|page=Part 1}}{{card|title=A}}")
+	c := &Client{HTTPClient: s.Client()}
+	at := time.Now()
+
+	for _, tt := range []struct {
+		name    string
+		call    func() error
+		host    bool
+		page    string
+		subpage string
+	}{
+		{"Get host", func() error { _, err := c.Get("https://example.com/index.php/Test_Deck"); return err }, true, "", ""},
+		{"Get page", func() error { _, err := c.Get("https://dvorakgame.co.uk/index.php/Missing"); return err }, false, "Missing", ""},
+		{"Get subpage", func() error { _, err := c.Get("https://dvorakgame.co.uk/index.php/Test_Deck"); return err }, false, "Test Deck/Part 1", "Test Deck/Part 1"},
+		{"Fetch page", func() error { _, err := c.Fetch("https://dvorakgame.co.uk/index.php/Missing"); return err }, false, "Missing", ""},
+		{"Fetch subpage", func() error { _, err := c.Fetch("https://dvorakgame.co.uk/index.php/Test_Deck"); return err }, false, "Test Deck/Part 1", "Test Deck/Part 1"},
+		{"History host", func() error { _, err := c.History("https://example.com/index.php/Test_Deck"); return err }, true, "", ""},
+		{"History page", func() error { _, err := c.History("https://dvorakgame.co.uk/index.php/Missing"); return err }, false, "Missing", ""},
+		{"GetAt subpage", func() error { _, err := c.GetAt("https://dvorakgame.co.uk/index.php/Test_Deck", at); return err }, false, "Test Deck/Part 1", "Test Deck/Part 1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil {
+				t.Fatal("got nil error")
+			}
+			if got := errors.Is(err, ErrInvalidHost); got != tt.host {
+				t.Errorf("errors.Is(%v, ErrInvalidHost) = %v, want %v", err, got, tt.host)
+			}
+			var pe *PageNotFoundError
+			if errors.As(err, &pe) != (tt.page != "") || (pe != nil && pe.Title != tt.page) {
+				t.Errorf("%v: got PageNotFoundError %+v, want title %q", err, pe, tt.page)
+			}
+			var se *SubpageError
+			if errors.As(err, &se) != (tt.subpage != "") || (se != nil && se.Title != tt.subpage) {
+				t.Errorf("%v: got SubpageError %+v, want title %q", err, se, tt.subpage)
+			}
+		})
+	}
+}
+
+func TestHTTPStatusError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer ts.Close()
+	c := &Client{Site: &Site{URL: ts.URL}}
+
+	_, err := c.Get(ts.URL + "/index.php/Test_Deck")
+	var se *HTTPStatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusForbidden {
+		t.Errorf("Get: got %v, want an HTTPStatusError with status 403", err)
+	}
+	var pe *PageNotFoundError
+	if errors.As(err, &pe) {
+		t.Errorf("Get: got PageNotFoundError %v for status 403", pe)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	c := &Client{HTTPClient: s.Client()}
+	var v struct{}
+	err := c.queryAPI(url.Values{"action": {"bogus"}}, &v)
+	var ae *APIError
+	if !errors.As(err, &ae) || ae.Code != "badvalue" {
+		t.Errorf("queryAPI: got %v, want an APIError with code badvalue", err)
+	}
+}
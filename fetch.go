@@ -0,0 +1,179 @@
+package dvorak
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// A Page is a revision of a wiki page.
+type Page struct {
+	// Title is the title of the page. If the requested title is a redirect,
+	// Title is the title of the redirect's target.
+	Title string `json:"title"`
+
+	// Revision is the revision of the page.
+	Revision Revision `json:"revision"`
+
+	// Content is the source code of the page as of the revision.
+	Content string `json:"content"`
+}
+
+// A DeckSource is the source code of a deck, page by page,
+// with the revision of each page.
+type DeckSource struct {
+	// Title is the title of the deck's page.
+	Title string `json:"title"`
+
+	// RedirectedFrom is the requested title of the deck's page
+	// if it redirects to Title, or empty otherwise.
+	RedirectedFrom string `json:"redirectedFrom,omitempty"`
+
+	// Main is the deck's page.
+	Main Page `json:"main"`
+
+	// Subpages are the deck's subpages in order.
+	Subpages []Page `json:"subpages,omitempty"`
+}
+
+// Source returns the source code of d, beginning with its subpages
+// in order, like Get.
+func (d *DeckSource) Source() []byte {
+	var b []byte
+	for _, p := range d.Subpages {
+		b = append(b, p.Content...)
+	}
+	return append(b, d.Main.Content...)
+}
+
+// Fetch returns the source code of the latest revision of the deck at
+// rawURL and its subpages through the MediaWiki API. It uses DefaultClient.
+func Fetch(rawURL string) (*DeckSource, error) { return DefaultClient.Fetch(rawURL) }
+
+// Fetch returns the source code of the latest revision of the deck at
+// rawURL and its subpages through the MediaWiki API.
+//
+// Unlike Get, Fetch follows redirects and returns the revision of each
+// page. It reads the deck's page in one request and all of its subpages
+// in another, rather than requesting each page separately. The requests
+// cannot be combined: the titles of the subpages are only known from the
+// content of the deck's page, and they are relative to the target of
+// any redirect.
+// rawURL must not specify a revision; use GetRevision instead.
+func (c *Client) Fetch(rawURL string) (*DeckSource, error) {
+	ref, err := c.site().ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if ref.RevID != 0 {
+		return nil, fmt.Errorf("%v: Fetch reads latest revisions; use GetRevision", rawURL)
+	}
+	if ref.Title == "" {
+		return nil, fmt.Errorf("%v: no page title", rawURL)
+	}
+
+	pages, err := c.latestRevisions([]string{ref.Title})
+	if err != nil {
+		return nil, err
+	}
+	main, ok := pages[ref.Title]
+	if !ok {
+		return nil, &PageNotFoundError{Title: ref.Title}
+	}
+	d := &DeckSource{Title: main.Title, Main: main}
+	if main.Title != ref.Title {
+		d.RedirectedFrom = ref.Title
+	}
+
+	// Subpages are relative to the redirect's target.
+	var titles []string
+	for _, sp := range parsePage([]byte(main.Content), nil).subpages {
+		titles = append(titles, canonicalTitle(main.Title+"/"+sp.page))
+	}
+	if len(titles) == 0 {
+		return d, nil
+	}
+	pages, err = c.latestRevisions(titles)
+	if err != nil {
+		return nil, err
+	}
+	for _, title := range titles {
+		p, ok := pages[title]
+		if !ok {
+			return nil, &SubpageError{Title: title, Err: &PageNotFoundError{Title: title}}
+		}
+		d.Subpages = append(d.Subpages, p)
+	}
+	return d, nil
+}
+
+// latestRevisions returns the latest revision of each page in titles
+// that exists, keyed by title, following redirects.
+func (c *Client) latestRevisions(titles []string) (map[string]Page, error) {
+	// maxTitles is the number of titles MediaWiki allows in a query.
+	const maxTitles = 50
+
+	m := make(map[string]Page)
+	for len(titles) > 0 {
+		n := len(titles)
+		if n > maxTitles {
+			n = maxTitles
+		}
+		params := url.Values{
+			"titles":    {strings.Join(titles[:n], "|")},
+			"rvprop":    {"ids|timestamp|user|comment|content"},
+			"rvslots":   {"main"},
+			"redirects": {"1"},
+		}
+		resolve := make(map[string]string)
+		pages := make(map[string]Page)
+		for {
+			rq, err := c.queryRevisions(params)
+			if err != nil {
+				return nil, err
+			}
+			for _, tm := range rq.Query.Normalized {
+				resolve[tm.From] = tm.To
+			}
+			for _, tm := range rq.Query.Redirects {
+				resolve[tm.From] = tm.To
+			}
+			for _, p := range rq.Query.Pages {
+				for _, r := range p.Revisions {
+					pages[p.Title] = Page{
+						Title: p.Title,
+						Revision: Revision{
+							ID:        r.RevID,
+							Timestamp: r.Timestamp,
+							User:      r.User,
+							Comment:   r.Comment,
+						},
+						Content: r.Slots.Main.Content + r.Content,
+					}
+				}
+			}
+			// MediaWiki omits the content of some pages
+			// if the result would be too large.
+			if rq.Continue == nil {
+				break
+			}
+			for k, v := range rq.Continue {
+				params.Set(k, v)
+			}
+		}
+		for _, title := range titles[:n] {
+			// Follow normalization and then any redirect.
+			t := title
+			for i := 0; i < 2; i++ {
+				if to, ok := resolve[t]; ok {
+					t = to
+				}
+			}
+			if p, ok := pages[t]; ok {
+				m[title] = p
+			}
+		}
+		titles = titles[n:]
+	}
+	return m, nil
+}
@@ -0,0 +1,73 @@
+package dvorak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dkmccandless/dvorak/dvoraktest"
+	"kr.dev/diff"
+)
+
+func TestFetch(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	t0 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.AddRevision("Test Deck", dvoraktest.Revision{
+		ID: 7, Timestamp: t0, User: "Alice", Comment: "new deck",
+		Content: "{{Subpage|page=Part_1}}{{Subpage|page=Part 2}}{{card|title=C}}",
+	})
+	s.AddRevision("Test Deck/Part 1", dvoraktest.Revision{ID: 8, Timestamp: t0.Add(time.Hour), User: "Bob", Content: "{{card|title=A}}"})
+	s.AddRevision("Test Deck/Part 2", dvoraktest.Revision{ID: 9, Timestamp: t0.Add(2 * time.Hour), User: "Bob", Content: "{{card|title=B}}"})
+	s.SetRedirect("Old Deck", "Test Deck")
+	c := &Client{HTTPClient: s.Client()}
+
+	want := &DeckSource{
+		Title:          "Test Deck",
+		RedirectedFrom: "Old Deck",
+		Main: Page{
+			Title:    "Test Deck",
+			Revision: Revision{ID: 7, Timestamp: t0, User: "Alice", Comment: "new deck"},
+			Content:  "{{Subpage|page=Part_1}}{{Subpage|page=Part 2}}{{card|title=C}}",
+		},
+		Subpages: []Page{
+			{
+				Title:    "Test Deck/Part 1",
+				Revision: Revision{ID: 8, Timestamp: t0.Add(time.Hour), User: "Bob"},
+				Content:  "{{card|title=A}}",
+			},
+			{
+				Title:    "Test Deck/Part 2",
+				Revision: Revision{ID: 9, Timestamp: t0.Add(2 * time.Hour), User: "Bob"},
+				Content:  "{{card|title=B}}",
+			},
+		},
+	}
+	got, err := c.Fetch("https://dvorakgame.co.uk/index.php/Old_Deck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff.Test(t, t.Errorf, got, want)
+	if n := s.Requests(); n != 2 {
+		t.Errorf("Fetch made %d requests, want 2", n)
+	}
+	if src, want := string(got.Source()), "{{card|title=A}}{{card|title=B}}"+want.Main.Content; src != want {
+		t.Errorf("Source() = %q, want %q", src, want)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	s.SetPage("Broken Deck", "{{Subpage|page=Missing}}")
+	c := &Client{HTTPClient: s.Client()}
+	for _, u := range []string{
+		"https://dvorakgame.co.uk/index.php/Missing",
+		"https://dvorakgame.co.uk/index.php/Broken_Deck",
+		"https://dvorakgame.co.uk/index.php?title=Broken_Deck&oldid=1",
+		"https://example.com/index.php/Broken_Deck",
+	} {
+		if _, err := c.Fetch(u); err == nil {
+			t.Errorf("Fetch(%q): got nil error", u)
+		}
+	}
+}
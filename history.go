@@ -0,0 +1,236 @@
+package dvorak
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Revision is a version of a wiki page.
+type Revision struct {
+	// ID is the revision ID, which identifies the revision among
+	// the revisions of all pages of the wiki.
+	ID int64 `json:"id"`
+
+	// Timestamp is the time at which the revision was saved.
+	Timestamp time.Time `json:"timestamp"`
+
+	// User is the name of the user who saved the revision.
+	User string `json:"user"`
+
+	// Comment is the edit summary.
+	Comment string `json:"comment"`
+}
+
+// revisionsQuery is the relevant part of the MediaWiki API's revisions
+// query result in format version 2.
+type revisionsQuery struct {
+	Continue map[string]string
+	Query    struct {
+		// Normalized and Redirects map each requested title
+		// to its normalized form and each redirect to its target.
+		Normalized []titleMapping
+		Redirects  []titleMapping
+
+		Pages []struct {
+			Title     string
+			Missing   bool
+			Revisions []struct {
+				RevID     int64
+				Timestamp time.Time
+				User      string
+				Comment   string
+
+				// Content is the revision's content before MediaWiki 1.32,
+				// and Slots.Main.Content is its content since.
+				Content string
+				Slots   struct {
+					Main struct {
+						Content string
+					}
+				}
+			}
+		}
+	}
+}
+
+// queryRevisions returns the result of a revisions query with params.
+func (c *Client) queryRevisions(params url.Values) (*revisionsQuery, error) {
+	params.Set("action", "query")
+	params.Set("prop", "revisions")
+	var rq revisionsQuery
+	if err := c.queryAPI(params, &rq); err != nil {
+		return nil, err
+	}
+	return &rq, nil
+}
+
+// A PageHistory is the revision history of a wiki page.
+type PageHistory struct {
+	// Title is the title of the page. If the requested title is a redirect,
+	// Title is the title of the redirect's target.
+	Title string `json:"title"`
+
+	// RedirectedFrom is the requested title of the page
+	// if it redirects to Title, or empty otherwise.
+	RedirectedFrom string `json:"redirectedFrom,omitempty"`
+
+	// Revisions are the revisions of the page, most recent first.
+	Revisions []Revision `json:"revisions"`
+}
+
+// History returns the revision history of the deck page at rawURL,
+// following any redirect. It does not include the revisions of subpages.
+// It uses DefaultClient.
+func History(rawURL string) (*PageHistory, error) { return DefaultClient.History(rawURL) }
+
+// History returns the revision history of the deck page at rawURL,
+// following any redirect. It does not include the revisions of subpages.
+func (c *Client) History(rawURL string) (*PageHistory, error) {
+	title, err := c.site().pageTitle(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{
+		"titles":    {title},
+		"rvprop":    {"ids|timestamp|user|comment"},
+		"rvlimit":   {"max"},
+		"redirects": {"1"},
+	}
+	h := &PageHistory{Title: title}
+	for {
+		rq, err := c.queryRevisions(params)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range rq.Query.Pages {
+			if p.Missing {
+				return nil, &PageNotFoundError{Title: title}
+			}
+			if p.Title != title {
+				h.Title, h.RedirectedFrom = p.Title, title
+			}
+			for _, r := range p.Revisions {
+				h.Revisions = append(h.Revisions, Revision{
+					ID:        r.RevID,
+					Timestamp: r.Timestamp,
+					User:      r.User,
+					Comment:   r.Comment,
+				})
+			}
+		}
+		if rq.Continue == nil {
+			return h, nil
+		}
+		for k, v := range rq.Continue {
+			params.Set(k, v)
+		}
+	}
+}
+
+// GetRevision returns the source code of the deck at rawURL as of the
+// revision with the given ID, beginning with its subpages in order, if any.
+// Each subpage is read at its latest revision as of the deck's revision.
+// It uses DefaultClient.
+func GetRevision(rawURL string, id int64) ([]byte, error) {
+	return DefaultClient.GetRevision(rawURL, id)
+}
+
+// GetRevision returns the source code of the deck at rawURL as of the
+// revision with the given ID, beginning with its subpages in order, if any.
+// Each subpage is read at its latest revision as of the deck's revision.
+func (c *Client) GetRevision(rawURL string, id int64) ([]byte, error) {
+	ref, err := c.site().ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return c.getRevision(ref.Title, id)
+}
+
+// getRevision returns the source code of the deck with the given title
+// as of the revision with the given ID, beginning with its subpages.
+// If title is empty, the deck is the page of the revision.
+func (c *Client) getRevision(title string, id int64) ([]byte, error) {
+	rq, err := c.queryRevisions(url.Values{
+		"revids":  {strconv.FormatInt(id, 10)},
+		"rvprop":  {"ids|timestamp|content"},
+		"rvslots": {"main"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rq.Query.Pages) != 1 || len(rq.Query.Pages[0].Revisions) != 1 {
+		return nil, fmt.Errorf("revision %d does not exist", id)
+	}
+	p := rq.Query.Pages[0]
+	if title == "" {
+		title = p.Title
+	}
+	if !strings.EqualFold(p.Title, title) {
+		return nil, fmt.Errorf("revision %d is of page %q, not %q", id, p.Title, title)
+	}
+	r := p.Revisions[0]
+	main := r.Slots.Main.Content + r.Content
+	return c.withSubpages(title, []byte(main), r.Timestamp)
+}
+
+// GetAt returns the source code of the deck at rawURL as it was at time t,
+// beginning with its subpages in order, if any.
+// It uses DefaultClient.
+func GetAt(rawURL string, t time.Time) ([]byte, error) { return DefaultClient.GetAt(rawURL, t) }
+
+// GetAt returns the source code of the deck at rawURL as it was at time t,
+// beginning with its subpages in order, if any.
+func (c *Client) GetAt(rawURL string, t time.Time) ([]byte, error) {
+	title, err := c.site().pageTitle(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	main, err := c.contentAt(title, t)
+	if err != nil {
+		return nil, err
+	}
+	return c.withSubpages(title, main, t)
+}
+
+// withSubpages returns the source code of the subpages of the page
+// with the given title and content as of time t, followed by main.
+func (c *Client) withSubpages(title string, main []byte, t time.Time) ([]byte, error) {
+	var b []byte
+	for _, sp := range parsePage(main, nil).subpages {
+		spTitle := canonicalTitle(title + "/" + sp.page)
+		sb, err := c.contentAt(spTitle, t)
+		if err != nil {
+			return nil, &SubpageError{Title: spTitle, Err: err}
+		}
+		b = append(b, sb...)
+	}
+	return append(b, main...), nil
+}
+
+// contentAt returns the content of the latest revision of the page
+// with the given title as of time t.
+func (c *Client) contentAt(title string, t time.Time) ([]byte, error) {
+	rq, err := c.queryRevisions(url.Values{
+		"titles":  {title},
+		"rvprop":  {"ids|timestamp|content"},
+		"rvslots": {"main"},
+		"rvlimit": {"1"},
+		"rvdir":   {"older"},
+		"rvstart": {t.UTC().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range rq.Query.Pages {
+		if p.Missing {
+			return nil, &PageNotFoundError{Title: title}
+		}
+		for _, r := range p.Revisions {
+			return []byte(r.Slots.Main.Content + r.Content), nil
+		}
+	}
+	return nil, fmt.Errorf("%v: no revision as of %v", title, t.Format(time.RFC3339))
+}
@@ -0,0 +1,81 @@
+package dvorak
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dkmccandless/dvorak/dvoraktest"
+	"kr.dev/diff"
+)
+
+func TestHistory(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	// Limit the results of each request to two revisions,
+	// so that History must continue the query.
+	s.MaxLimit = 2
+	s.AddRevision("Test Deck", dvoraktest.Revision{ID: 1, Timestamp: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), User: "Alice", Comment: "New deck"})
+	s.AddRevision("Test Deck", dvoraktest.Revision{ID: 2, Timestamp: time.Date(2021, 2, 1, 12, 0, 0, 0, time.UTC), User: "Bob"})
+	s.AddRevision("Test Deck", dvoraktest.Revision{ID: 3, Timestamp: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC), User: "Alice", Comment: "Add Zap"})
+	s.SetRedirect("Old Deck", "Test Deck")
+	c := &Client{HTTPClient: s.Client()}
+
+	revs := []Revision{
+		{ID: 3, Timestamp: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC), User: "Alice", Comment: "Add Zap"},
+		{ID: 2, Timestamp: time.Date(2021, 2, 1, 12, 0, 0, 0, time.UTC), User: "Bob"},
+		{ID: 1, Timestamp: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), User: "Alice", Comment: "New deck"},
+	}
+	for _, tt := range []struct {
+		url  string
+		want *PageHistory
+	}{
+		{"https://dvorakgame.co.uk/index.php/Test_Deck", &PageHistory{Title: "Test Deck", Revisions: revs}},
+		{"https://dvorakgame.co.uk/index.php/Old_Deck", &PageHistory{Title: "Test Deck", RedirectedFrom: "Old Deck", Revisions: revs}},
+	} {
+		got, err := c.History(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff.Test(t, t.Errorf, got, tt.want)
+	}
+}
+
+func TestGetRevision(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	s.AddRevision("Test Deck/Part 1", dvoraktest.Revision{ID: 1, Timestamp: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Content: "{{card|title=A}}"})
+	s.AddRevision("Test Deck", dvoraktest.Revision{ID: 2, Timestamp: time.Date(2021, 2, 1, 12, 0, 0, 0, time.UTC), Content: "{{subpage|page=Part_1}}{{card|title=B}}"})
+	// A later revision of the subpage is not part of revision 2.
+	s.AddRevision("Test Deck/Part 1", dvoraktest.Revision{ID: 3, Timestamp: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), Content: "{{card|title=Z}}"})
+	c := &Client{HTTPClient: s.Client()}
+
+	got, err := c.GetRevision("https://dvorakgame.co.uk/index.php/Test_Deck", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{{card|title=A}}{{subpage|page=Part_1}}{{card|title=B}}"; string(got) != want {
+		t.Errorf("GetRevision: got %q, want %q", got, want)
+	}
+}
+
+func TestGetAt(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	s.AddRevision("Test Deck", dvoraktest.Revision{Timestamp: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Content: "{{card|title=A}}"})
+	s.AddRevision("Test Deck", dvoraktest.Revision{Timestamp: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), Content: "{{card|title=B}}"})
+	c := &Client{HTTPClient: s.Client()}
+
+	got, err := c.GetAt("https://dvorakgame.co.uk/index.php/Test_Deck", time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{{card|title=A}}"; string(got) != want {
+		t.Errorf("GetAt: got %q, want %q", got, want)
+	}
+	if _, err := c.GetAt("https://dvorakgame.co.uk/index.php/Test_Deck", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("GetAt before the first revision: got nil error")
+	}
+	if _, err := c.GetAt("https://dvorakgame.co.uk/index.php/Missing", time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("GetAt of a missing page: got nil error")
+	}
+}
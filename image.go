@@ -1,90 +1,299 @@
 package dvorak
 
 import (
-	"encoding/json"
-	"io"
-	"net/http"
+	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
-// imageInfo is the relevant part of the MediaWiki API's imageinfo query result.
-type imageInfo struct {
+// An ImageInfo describes the image file named by a Card's Image.
+type ImageInfo struct {
+	// Name is the file's name in MediaWiki normalized form, with the
+	// first character capitalized and spaces instead of underscores,
+	// but without the "File:" namespace prefix. If Card.Image names
+	// a redirect, Name is the name of the redirect's target.
+	Name string `json:"name"`
+
+	// URL is the URL of the file, or empty if it is missing or invalid.
+	URL string `json:"url,omitempty"`
+
+	// SHA1 is the hexadecimal SHA-1 hash of the file's contents.
+	SHA1 string `json:"sha1,omitempty"`
+
+	// Width and Height are the dimensions of the image in pixels.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+
+	// MIME is the MIME type of the file, such as "image/png".
+	MIME string `json:"mime,omitempty"`
+
+	// ThumbURL is the URL of a thumbnail of the image scaled to fit
+	// the requested size, and ThumbWidth and ThumbHeight are its
+	// dimensions in pixels. They are empty if no size was requested.
+	// If the image is smaller than the requested size, ThumbURL is the
+	// URL of the original image.
+	ThumbURL    string `json:"thumbURL,omitempty"`
+	ThumbWidth  int    `json:"thumbWidth,omitempty"`
+	ThumbHeight int    `json:"thumbHeight,omitempty"`
+
+	// Missing indicates that the wiki has no file with the name.
+	Missing bool `json:"missing,omitempty"`
+
+	// Invalid indicates that the name is not a valid file name.
+	Invalid bool `json:"invalid,omitempty"`
+
+	// Credit is the image's attribution and licence,
+	// or nil if they were not requested.
+	Credit *ImageCredit `json:"credit,omitempty"`
+}
+
+// An ImageCredit is the attribution and licence of an image file,
+// from the metadata of its file description page. Each field is plain text
+// and is empty if the page does not specify it.
+type ImageCredit struct {
+	// Author is the creator of the image.
+	Author string `json:"author,omitempty"`
+
+	// Credit is the image's source or other attribution.
+	Credit string `json:"credit,omitempty"`
+
+	// License is the short name of the image's licence, such as "CC BY-SA 4.0".
+	License string `json:"license,omitempty"`
+
+	// LicenseURL is the URL of the licence.
+	LicenseURL string `json:"licenseURL,omitempty"`
+
+	// Description is the description of the image.
+	Description string `json:"description,omitempty"`
+
+	// Uploader is the name of the user who uploaded the latest version
+	// of the file.
+	Uploader string `json:"uploader,omitempty"`
+}
+
+// imageQuery is the relevant part of the MediaWiki API's imageinfo query
+// result in format version 2.
+type imageQuery struct {
 	Query struct {
-		Pages map[string]struct {
-			// Title is the filename in namespace normalized form
+		// Normalized and Redirects map each requested title
+		// to its normalized form and each redirect to its target.
+		Normalized []titleMapping
+		Redirects  []titleMapping
+
+		Pages []struct {
 			Title     string
+			Missing   bool
+			Invalid   bool
 			ImageInfo []struct {
-				URL string
+				URL         string
+				SHA1        string
+				Width       int
+				Height      int
+				MIME        string
+				ThumbURL    string
+				ThumbWidth  int
+				ThumbHeight int
+				User        string
+				ExtMetadata map[string]struct {
+					Value interface{}
+				}
 			}
 		}
 	}
 }
 
-// ImageURLs queries the Dvorak wiki API and returns a map of image filenames
-// to their URLs.
-//
-// Filenames are in MediaWiki normalized form, with the first character
-// capitalized and spaces instead of underscores, but without the "File:"
-// namespace prefix.
-func ImageURLs(cards []Card) (map[string]string, error) {
+// A titleMapping maps one page title to another.
+type titleMapping struct {
+	From string
+	To   string
+}
+
+// invalidTitleChars are the characters that MediaWiki forbids in titles.
+const invalidTitleChars = "#<>[]|{}"
+
+// ImageURLs queries the Dvorak wiki API and returns information about
+// the image of each card, keyed by Card.Image. It uses DefaultClient.
+func ImageURLs(cards []Card) (map[string]ImageInfo, error) { return DefaultClient.ImageURLs(cards) }
+
+// ImageURLs queries the Dvorak wiki API and returns information about
+// the image of each card, keyed by Card.Image. Cards without an image
+// are omitted.
+func (c *Client) ImageURLs(cards []Card) (map[string]ImageInfo, error) {
+	return c.Thumbnails(cards, 0, 0)
+}
+
+// Thumbnails is like ImageURLs, but also returns the URL of a thumbnail
+// of each image that fits within width and height. It uses DefaultClient.
+func Thumbnails(cards []Card, width, height int) (map[string]ImageInfo, error) {
+	return DefaultClient.Thumbnails(cards, width, height)
+}
+
+// Thumbnails is like ImageURLs, but also returns the URL of a thumbnail
+// of each image scaled to fit within width and height, preserving its
+// aspect ratio. If width or height is 0, it does not constrain the size.
+// If both are 0, no thumbnails are returned.
+func (c *Client) Thumbnails(cards []Card, width, height int) (map[string]ImageInfo, error) {
+	return c.imageInfos(cards, imageRequest{width: width, height: height})
+}
+
+// ImageCredits is like ImageURLs, but also returns the Credit of each image.
+// It uses DefaultClient.
+func ImageCredits(cards []Card) (map[string]ImageInfo, error) {
+	return DefaultClient.ImageCredits(cards)
+}
+
+// ImageCredits is like ImageURLs, but also returns the Credit of each image.
+func (c *Client) ImageCredits(cards []Card) (map[string]ImageInfo, error) {
+	return c.imageInfos(cards, imageRequest{credits: true})
+}
+
+// An imageRequest specifies the optional information to query about images.
+type imageRequest struct {
+	// width and height are the size of thumbnails, if not 0.
+	width, height int
+
+	// credits indicates whether to query attribution and licences.
+	credits bool
+}
+
+// imageInfos returns the information specified by req about the image
+// of each card, keyed by Card.Image.
+func (c *Client) imageInfos(cards []Card, req imageRequest) (map[string]ImageInfo, error) {
 	// maxTitles is the number of titles MediaWiki allows in a query.
 	const maxTitles = 50
 
+	m := make(map[string]ImageInfo)
 	var images []string
-	for _, c := range cards {
-		if c.Image != "" {
-			images = append(images, "File:"+c.Image)
+	for _, card := range cards {
+		if card.Image == "" {
+			continue
 		}
+		if _, ok := m[card.Image]; ok {
+			continue
+		}
+		if strings.ContainsAny(card.Image, invalidTitleChars) {
+			m[card.Image] = ImageInfo{Name: card.Image, Invalid: true}
+			continue
+		}
+		m[card.Image] = ImageInfo{}
+		images = append(images, card.Image)
 	}
-	if len(images) == 0 {
+	if len(m) == 0 {
 		return nil, nil
 	}
 
-	m := make(map[string]string)
 	for len(images) > 0 {
 		n := len(images)
 		if n > maxTitles {
 			n = maxTitles
 		}
-		urls, err := queryImages(images[:n])
+		infos, err := c.queryImages(images[:n], req)
 		if err != nil {
 			return nil, err
 		}
-		for name, url := range urls {
-			// The wiki's TLS certificate is for dvorakgame.co.uk
-			m[name] = strings.Replace(url, "www.", "", 1)
+		for name, info := range infos {
+			m[name] = info
 		}
 		images = images[n:]
 	}
 	return m, nil
 }
 
-// queryImages returns a map of normalized image filenames to their URLs.
-func queryImages(images []string) (map[string]string, error) {
-	// query is the query URL that the file names will be appended to.
+// queryImages returns the information specified by req about the named
+// images, keyed by name.
+func (c *Client) queryImages(images []string, req imageRequest) (map[string]ImageInfo, error) {
+	titles := make([]string, len(images))
+	for i, name := range images {
+		titles[i] = "File:" + name
+	}
 	// MediaWiki etiquette prefers batching files in a single query if possible.
 	// https://www.mediawiki.org/w/api.php?action=help&modules=query%2Bimageinfo
-	const query = "https://dvorakgame.co.uk/api.php?action=query&prop=imageinfo&iiprop=url&iilimit=1&format=json&titles="
-
-	titles := strings.ReplaceAll(strings.Join(images, "|"), " ", "_")
-	resp, err := http.Get(query + titles)
-	if err != nil {
-		return nil, err
+	params := url.Values{
+		"action":    {"query"},
+		"prop":      {"imageinfo"},
+		"iiprop":    {"url|sha1|size|mime"},
+		"iilimit":   {"1"},
+		"redirects": {"1"},
+		"titles":    {strings.Join(titles, "|")},
+	}
+	if req.width > 0 {
+		params.Set("iiurlwidth", strconv.Itoa(req.width))
+	}
+	if req.height > 0 {
+		params.Set("iiurlheight", strconv.Itoa(req.height))
 	}
-	defer resp.Body.Close()
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
+	if req.credits {
+		params.Set("iiprop", params.Get("iiprop")+"|user|extmetadata")
+		params.Set("iiextmetadatafilter", "Artist|Credit|LicenseShortName|LicenseUrl|ImageDescription")
+	}
+	var iq imageQuery
+	if err := c.queryAPI(params, &iq); err != nil {
 		return nil, err
 	}
-	var ii imageInfo
-	json.Unmarshal(b, &ii)
-
-	m := make(map[string]string)
-	for _, p := range ii.Query.Pages {
-		for _, s := range p.ImageInfo {
-			title := strings.TrimPrefix(p.Title, "File:")
-			m[title] = s.URL
+
+	resolve := make(map[string]string)
+	for _, tm := range iq.Query.Normalized {
+		resolve[tm.From] = tm.To
+	}
+	for _, tm := range iq.Query.Redirects {
+		resolve[tm.From] = tm.To
+	}
+	pages := make(map[string]ImageInfo)
+	for _, p := range iq.Query.Pages {
+		info := ImageInfo{
+			Name:    strings.TrimPrefix(p.Title, "File:"),
+			Missing: p.Missing,
+			Invalid: p.Invalid,
+		}
+		for _, ii := range p.ImageInfo {
+			info.URL = c.site().fileURL(ii.URL)
+			info.SHA1 = ii.SHA1
+			info.Width, info.Height = ii.Width, ii.Height
+			info.MIME = ii.MIME
+			if ii.ThumbURL != "" {
+				info.ThumbURL = c.site().fileURL(ii.ThumbURL)
+				info.ThumbWidth, info.ThumbHeight = ii.ThumbWidth, ii.ThumbHeight
+			}
+			if req.credits {
+				meta := func(key string) string {
+					// Values are usually HTML strings, but may be numbers.
+					v := ii.ExtMetadata[key].Value
+					if v == nil {
+						return ""
+					}
+					return strings.TrimSpace(plainText(parseHTML(fmt.Sprint(v))))
+				}
+				info.Credit = &ImageCredit{
+					Author:      meta("Artist"),
+					Credit:      meta("Credit"),
+					License:     meta("LicenseShortName"),
+					LicenseURL:  meta("LicenseUrl"),
+					Description: meta("ImageDescription"),
+					Uploader:    ii.User,
+				}
+			}
+		}
+		if info.URL == "" && !info.Invalid {
+			info.Missing = true
+		}
+		pages[p.Title] = info
+	}
+
+	m := make(map[string]ImageInfo)
+	for i, name := range images {
+		title := titles[i]
+		// Follow normalization and then any redirect.
+		for n := 0; n < 2; n++ {
+			if to, ok := resolve[title]; ok {
+				title = to
+			}
+		}
+		info, ok := pages[title]
+		if !ok {
+			info = ImageInfo{Name: strings.TrimPrefix(title, "File:"), Missing: true}
 		}
+		m[name] = info
 	}
 	return m, nil
 }
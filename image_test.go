@@ -0,0 +1,215 @@
+package dvorak
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dkmccandless/dvorak/dvoraktest"
+	"kr.dev/diff"
+)
+
+// encodeImage returns a width by height image in the format
+// of the file extension of name, ".png" or ".gif".
+func encodeImage(t *testing.T, name string, width, height int) []byte {
+	t.Helper()
+	img := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.Black})
+	var b bytes.Buffer
+	var err error
+	if strings.HasSuffix(name, ".gif") {
+		err = gif.Encode(&b, img, nil)
+	} else {
+		err = png.Encode(&b, img)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// serveFiles returns a fake wiki with image files of the given names and
+// sizes, and a Client that uses it. It returns the files' contents by name.
+func serveFiles(t *testing.T, sizes map[string]image.Point) (*dvoraktest.Server, *Client, map[string][]byte) {
+	t.Helper()
+	s := dvoraktest.NewServer()
+	t.Cleanup(s.Close)
+	files := make(map[string][]byte)
+	for name, size := range sizes {
+		files[name] = encodeImage(t, name, size.X, size.Y)
+		s.SetFile(name, dvoraktest.File{Content: files[name], User: "Binarius"})
+	}
+	return s, &Client{HTTPClient: s.Client()}, files
+}
+
+// sha1Hex returns the hexadecimal SHA-1 hash of b.
+func sha1Hex(b []byte) string {
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkURLs reports an error unless the URL of each of infos serves the
+// content of the file it names in files, and the ThumbURL of each serves
+// the same content from a URL containing thumb if the thumbnail is
+// smaller. It then clears the URLs, which depend on the fake wiki's
+// layout, so that infos can be compared to values that omit them.
+func checkURLs(t *testing.T, c *Client, infos map[string]ImageInfo, files map[string][]byte, thumb string) {
+	t.Helper()
+	for name, info := range infos {
+		for _, u := range []string{info.URL, info.ThumbURL} {
+			if u == "" {
+				continue
+			}
+			resp, err := c.HTTPClient.Get(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil || !bytes.Equal(b, files[info.Name]) {
+				t.Errorf("%s: %s: got %d bytes, %v; want the content of %s", name, u, len(b), err, info.Name)
+			}
+		}
+		if info.ThumbWidth < info.Width && !strings.Contains(info.ThumbURL, thumb) {
+			t.Errorf("%s: thumbnail URL %s does not contain %q", name, info.ThumbURL, thumb)
+		}
+		info.URL, info.ThumbURL = "", ""
+		infos[name] = info
+	}
+}
+
+func TestImageURLs(t *testing.T) {
+	s, c, files := serveFiles(t, map[string]image.Point{
+		"Fish.png":        {4, 2},
+		"Golden fish.png": {3, 3},
+		"New.png":         {2, 4},
+	})
+	s.SetRedirect("File:Old.png", "File:New.png")
+	cards := Parse([]byte(`{{card|title=A|image=Fish.png}}{{card|title=B|image=golden_fish.png}}
+{{card|title=C|image=Old.png}}{{card|title=D|image=Gone.png}}{{card|title=E|image=a#1.png}}
+{{card|title=F|image=Fish.png}}{{card|title=G}}`))
+	got, err := c.ImageURLs(cards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkURLs(t, c, got, files, "")
+	want := map[string]ImageInfo{
+		"Fish.png":        {Name: "Fish.png", SHA1: sha1Hex(files["Fish.png"]), Width: 4, Height: 2, MIME: "image/png"},
+		"golden_fish.png": {Name: "Golden fish.png", SHA1: sha1Hex(files["Golden fish.png"]), Width: 3, Height: 3, MIME: "image/png"},
+		"Old.png":         {Name: "New.png", SHA1: sha1Hex(files["New.png"]), Width: 2, Height: 4, MIME: "image/png"},
+		"Gone.png":        {Name: "Gone.png", Missing: true},
+		"a#1.png":         {Name: "a#1.png", Invalid: true},
+	}
+	diff.Test(t, t.Errorf, got, want)
+}
+
+func TestImageURLsError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error": {"code": "readapidenied", "info": "You need read permission to use this module."}}`))
+	}))
+	defer ts.Close()
+	c := &Client{Site: &Site{URL: ts.URL}}
+	if _, err := c.ImageURLs(Parse([]byte("{{card|title=A|image=Fish.png}}"))); err == nil {
+		t.Errorf("ImageURLs: got nil error")
+	}
+}
+
+func TestImageURLsNone(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	c := &Client{HTTPClient: s.Client()}
+	got, err := c.ImageURLs(Parse([]byte("{{card|title=A}}")))
+	if got != nil || err != nil {
+		t.Errorf("ImageURLs: got %v, %v; want nil, nil", got, err)
+	}
+	if n := s.Requests(); n != 0 {
+		t.Errorf("ImageURLs made %d requests, want 0", n)
+	}
+}
+
+func TestThumbnails(t *testing.T) {
+	_, c, files := serveFiles(t, map[string]image.Point{
+		"Big.png":   {2000, 1000},
+		"Small.gif": {100, 50},
+	})
+	got, err := c.Thumbnails(Parse([]byte("{{card|title=A|image=Big.png}}{{card|title=B|image=Small.gif}}")), 200, 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkURLs(t, c, got, files, "200px-Big.png")
+	want := map[string]ImageInfo{
+		"Big.png": {
+			Name:        "Big.png",
+			SHA1:        sha1Hex(files["Big.png"]),
+			Width:       2000,
+			Height:      1000,
+			MIME:        "image/png",
+			ThumbWidth:  200,
+			ThumbHeight: 100,
+		},
+		"Small.gif": {
+			Name:        "Small.gif",
+			SHA1:        sha1Hex(files["Small.gif"]),
+			Width:       100,
+			Height:      50,
+			MIME:        "image/gif",
+			ThumbWidth:  100,
+			ThumbHeight: 50,
+		},
+	}
+	diff.Test(t, t.Errorf, got, want)
+}
+
+func TestImageCredits(t *testing.T) {
+	s, c, files := serveFiles(t, map[string]image.Point{"Bare.png": {1, 1}})
+	files["Fish.png"] = encodeImage(t, "Fish.png", 1, 1)
+	s.SetFile("Fish.png", dvoraktest.File{
+		Content: files["Fish.png"],
+		User:    "Binarius",
+		Metadata: map[string]string{
+			"Artist":           `<a href="/index.php/User:Alice">Alice</a> &amp; Bob`,
+			"LicenseShortName": "CC BY-SA 4.0",
+			"LicenseUrl":       "https://creativecommons.org/licenses/by-sa/4.0",
+			"ImageDescription": "A <i>golden</i> fish.\n",
+			"DateTime":         "2021-01-01 00:00:00",
+		},
+	})
+	got, err := c.ImageCredits(Parse([]byte("{{card|title=A|image=Fish.png}}{{card|title=B|image=Bare.png}}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkURLs(t, c, got, files, "")
+	want := map[string]ImageInfo{
+		"Fish.png": {
+			Name:   "Fish.png",
+			SHA1:   sha1Hex(files["Fish.png"]),
+			Width:  1,
+			Height: 1,
+			MIME:   "image/png",
+			Credit: &ImageCredit{
+				Author:      "Alice & Bob",
+				License:     "CC BY-SA 4.0",
+				LicenseURL:  "https://creativecommons.org/licenses/by-sa/4.0",
+				Description: "A golden fish.",
+				Uploader:    "Binarius",
+			},
+		},
+		"Bare.png": {
+			Name:   "Bare.png",
+			SHA1:   sha1Hex(files["Bare.png"]),
+			Width:  1,
+			Height: 1,
+			MIME:   "image/png",
+			Credit: &ImageCredit{Uploader: "Binarius"},
+		},
+	}
+	diff.Test(t, t.Errorf, got, want)
+}
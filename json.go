@@ -0,0 +1,148 @@
+package dvorak
+
+import (
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Deck is a named collection of Cards.
+type Deck struct {
+	// Name is the deck's name, usually the title of its wiki page.
+	Name string `json:"name"`
+
+	// Cards lists the deck's cards in order.
+	Cards []Card `json:"cards"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// A Deck without cards is encoded with an empty array of cards.
+func (d Deck) MarshalJSON() ([]byte, error) {
+	// deck has the fields of Deck but not its methods.
+	type deck Deck
+	if d.Cards == nil {
+		d.Cards = []Card{}
+	}
+	return json.Marshal(deck(d))
+}
+
+// RichText is the JSON encoding of a Card field that holds HTML.
+type RichText struct {
+	// HTML is the rendered HTML of the field.
+	HTML string `json:"html"`
+
+	// Text is the field's text content without markup.
+	Text string `json:"text"`
+}
+
+// cardJSON is the JSON encoding of a Card.
+// Its field names are those of the wiki's card template parameters.
+// The schema is described by card.schema.json.
+type cardJSON struct {
+	ID          int       `json:"id"`
+	Title       *RichText `json:"title,omitempty"`
+	LongTitle   bool      `json:"longtitle,omitempty"`
+	Text        *RichText `json:"text,omitempty"`
+	LongText    bool      `json:"longtext,omitempty"`
+	Type        *RichText `json:"type,omitempty"`
+	BGColor     string    `json:"bgcolor,omitempty"`
+	CornerValue *RichText `json:"cornervalue,omitempty"`
+	Image       string    `json:"image,omitempty"`
+	ImgBack     string    `json:"imgback,omitempty"`
+	FlavorText  *RichText `json:"flavortext,omitempty"`
+	Creator     *RichText `json:"creator,omitempty"`
+	MiniCard    bool      `json:"minicard,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// Fields that hold HTML are encoded as objects containing both the rendered
+// HTML and its text content. Empty fields are omitted.
+func (c Card) MarshalJSON() ([]byte, error) {
+	title, err := richText(c.Title)
+	if err != nil {
+		return nil, err
+	}
+	text, err := richText(c.Text)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := richText(c.Type)
+	if err != nil {
+		return nil, err
+	}
+	corner, err := richText(c.CornerValue)
+	if err != nil {
+		return nil, err
+	}
+	flavor, err := richText(c.FlavorText)
+	if err != nil {
+		return nil, err
+	}
+	creator, err := richText(c.Creator)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cardJSON{
+		ID:          c.ID,
+		Title:       title,
+		LongTitle:   c.LongTitle,
+		Text:        text,
+		LongText:    c.LongText,
+		Type:        typ,
+		BGColor:     string(c.BGColor),
+		CornerValue: corner,
+		Image:       c.Image,
+		ImgBack:     string(c.ImgBack),
+		FlavorText:  flavor,
+		Creator:     creator,
+		MiniCard:    c.MiniCard,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// The HTML of rich fields is parsed and their text is ignored.
+func (c *Card) UnmarshalJSON(b []byte) error {
+	var cj cardJSON
+	if err := json.Unmarshal(b, &cj); err != nil {
+		return err
+	}
+	*c = Card{
+		Title:       fromRichText(cj.Title),
+		LongTitle:   cj.LongTitle,
+		Text:        fromRichText(cj.Text),
+		LongText:    cj.LongText,
+		Type:        fromRichText(cj.Type),
+		BGColor:     colorParam(cj.BGColor),
+		CornerValue: fromRichText(cj.CornerValue),
+		Image:       cj.Image,
+		ImgBack:     colorParam(cj.ImgBack),
+		FlavorText:  fromRichText(cj.FlavorText),
+		Creator:     fromRichText(cj.Creator),
+		MiniCard:    cj.MiniCard,
+		ID:          cj.ID,
+	}
+	return nil
+}
+
+// richText returns the RichText encoding of frag, or nil if frag is empty.
+func richText(frag []*html.Node) (*RichText, error) {
+	if len(frag) == 0 {
+		return nil, nil
+	}
+	var b strings.Builder
+	for _, n := range frag {
+		if err := html.Render(&b, n); err != nil {
+			return nil, err
+		}
+	}
+	return &RichText{HTML: b.String(), Text: plainText(frag)}, nil
+}
+
+// fromRichText parses the HTML of rt, or returns nil if rt is nil or empty.
+func fromRichText(rt *RichText) []*html.Node {
+	if rt == nil || rt.HTML == "" {
+		return nil
+	}
+	return parseHTML(rt.HTML)
+}
@@ -0,0 +1,172 @@
+package dvorak
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update card.schema.json")
+
+func TestCardJSON(t *testing.T) {
+	for _, tt := range []struct {
+		source, want string
+	}{
+		{"{{card}}", `{"id":1,"bgcolor":"666"}`},
+		{
+			"{{card|title=A|type=Action|text='''Action:''' Draw a card.<br>Discard a card.|minicard=y}}",
+			`{"id":1,` +
+				`"title":{"html":"A","text":"A"},` +
+				`"text":{"html":"\u003cb\u003eAction:\u003c/b\u003e Draw a card.\u003cbr/\u003eDiscard a card.","text":"Action: Draw a card.\nDiscard a card."},` +
+				`"type":{"html":"Action","text":"Action"},` +
+				`"bgcolor":"600","minicard":true}`,
+		},
+		{
+			"{{card|title=Fish &amp; Chips|image=Fish.png|imgback=FFD700|creator=[[User:Binarius|Binarius]]}}",
+			`{"id":1,` +
+				`"title":{"html":"Fish \u0026amp; Chips","text":"Fish \u0026 Chips"},` +
+				`"bgcolor":"666","image":"Fish.png","imgback":"FFD700",` +
+				`"creator":{"html":"Binarius","text":"Binarius"}}`,
+		},
+	} {
+		c := Parse([]byte(tt.source))[0]
+		b, err := json.Marshal(c)
+		if err != nil {
+			t.Fatalf("Marshal(%q): %v", tt.source, err)
+		}
+		if string(b) != tt.want {
+			t.Errorf("Marshal(%q):\ngot  %s\nwant %s", tt.source, b, tt.want)
+		}
+
+		var got Card
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", b, err)
+		}
+		b2, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("Marshal(%s): %v", b, err)
+		}
+		if !bytes.Equal(b, b2) {
+			t.Errorf("round trip of %q:\ngot  %s\nwant %s", tt.source, b2, b)
+		}
+	}
+}
+
+func TestDeckJSON(t *testing.T) {
+	d := Deck{
+		Name:  "Test",
+		Cards: Parse([]byte("{{card|title=A|type=Thing}}{{card|title=B}}")),
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Deck
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != d.Name || len(got.Cards) != len(d.Cards) {
+		t.Fatalf("round trip: got %+v, want %+v", got, d)
+	}
+	for i := range d.Cards {
+		if dump(got.Cards[i].Title) != dump(d.Cards[i].Title) ||
+			got.Cards[i].BGColor != d.Cards[i].BGColor ||
+			got.Cards[i].ID != d.Cards[i].ID {
+			t.Errorf("round trip card %d: got %+v, want %+v", i, got.Cards[i], d.Cards[i])
+		}
+	}
+}
+
+func TestEmptyDeckJSON(t *testing.T) {
+	b, err := json.Marshal(Deck{Name: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"name":"x","cards":[]}`; got != want {
+		t.Errorf("Marshal(empty deck) = %s, want %s", got, want)
+	}
+}
+
+func TestCardUnmarshalError(t *testing.T) {
+	var c Card
+	if err := json.Unmarshal([]byte(`{"title":"A"}`), &c); err == nil {
+		t.Error("Unmarshal of string title: got nil error")
+	}
+}
+
+// TestSchema checks that card.schema.json describes the JSON encoding of
+// Deck and Card. Run with -update to regenerate it.
+func TestSchema(t *testing.T) {
+	b, err := json.MarshalIndent(schema(), "", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = append(b, '\n')
+	if *update {
+		if err := os.WriteFile("card.schema.json", b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := os.ReadFile("card.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(file, b) {
+		t.Error("card.schema.json is out of date; run go test -run TestSchema -update")
+	}
+}
+
+// schema returns a JSON Schema for Deck generated from its JSON encoding.
+func schema() map[string]interface{} {
+	return map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         "https://github.com/dkmccandless/dvorak/card.schema.json",
+		"title":       "Deck",
+		"description": "A Dvorak deck as encoded by package dvorak.",
+		"type":        "object",
+		"properties": map[string]interface{}{
+			"name":  map[string]interface{}{"type": "string"},
+			"cards": map[string]interface{}{"type": "array", "items": map[string]string{"$ref": "#/$defs/card"}},
+		},
+		"required": []string{"name", "cards"},
+		"$defs": map[string]interface{}{
+			"card":     objectSchema(reflect.TypeOf(cardJSON{})),
+			"richtext": objectSchema(reflect.TypeOf(RichText{})),
+		},
+	}
+}
+
+// objectSchema returns a JSON Schema for values of the struct type t.
+func objectSchema(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if opts != "omitempty" {
+			required = append(required, name)
+		}
+		switch f.Type.Kind() {
+		case reflect.Bool:
+			props[name] = map[string]string{"type": "boolean"}
+		case reflect.Int:
+			props[name] = map[string]string{"type": "integer"}
+		case reflect.String:
+			props[name] = map[string]string{"type": "string"}
+		case reflect.Ptr:
+			props[name] = map[string]string{"$ref": "#/$defs/richtext"}
+		default:
+			panic("unsupported field type " + f.Type.String())
+		}
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           props,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
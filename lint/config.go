@@ -0,0 +1,154 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Default limits of Config.
+const (
+	DefaultMaxTitleLen = 24
+	DefaultMaxTextLen  = 250
+)
+
+// A Config configures the rules applied to a deck.
+// The zero value applies the rules that are enabled by default.
+type Config struct {
+	// Enable lists the names of rules to apply
+	// in addition to those enabled by default.
+	Enable []string `json:"enable,omitempty"`
+
+	// Disable lists the names of rules not to apply.
+	// It takes precedence over Enable.
+	Disable []string `json:"disable,omitempty"`
+
+	// MaxTitleLen is the number of characters of the longest title that
+	// fits the standard header. If zero, DefaultMaxTitleLen is used.
+	MaxTitleLen int `json:"maxTitleLen,omitempty"`
+
+	// MaxTextLen is the number of characters of the longest rule text that
+	// fits the standard text area. If zero, DefaultMaxTextLen is used.
+	MaxTextLen int `json:"maxTextLen,omitempty"`
+
+	// Images is the set of Card.Image values that can be found on the wiki.
+	// If nil, the unresolved-image rule reports nothing.
+	Images map[string]bool `json:"-"`
+}
+
+// enabled reports whether c enables r.
+func (c *Config) enabled(r *Rule) bool {
+	switch {
+	case contains(c.Disable, r.Name):
+		return false
+	case contains(c.Enable, r.Name):
+		return true
+	default:
+		return r.Default
+	}
+}
+
+func (c *Config) maxTitleLen() int {
+	if c.MaxTitleLen == 0 {
+		return DefaultMaxTitleLen
+	}
+	return c.MaxTitleLen
+}
+
+func (c *Config) maxTextLen() int {
+	if c.MaxTextLen == 0 {
+		return DefaultMaxTextLen
+	}
+	return c.MaxTextLen
+}
+
+// check returns an error if c names a rule that does not exist.
+func (c *Config) check() error {
+	for _, names := range [][]string{c.Enable, c.Disable} {
+		for _, name := range names {
+			if ruleNamed(name) == nil {
+				return fmt.Errorf("unknown rule %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// ruleNamed returns the rule with the given name, or nil if there is none.
+func ruleNamed(name string) *Rule {
+	for i := range Rules {
+		if Rules[i].Name == name {
+			return &Rules[i]
+		}
+	}
+	return nil
+}
+
+// Settings holds the Configs of a collection of decks.
+type Settings struct {
+	// Default is the Config of every deck.
+	Default Config `json:"default"`
+
+	// Decks maps deck names to Configs that modify Default.
+	Decks map[string]Config `json:"decks,omitempty"`
+}
+
+// ReadSettings reads Settings encoded as JSON from r.
+func ReadSettings(r io.Reader) (*Settings, error) {
+	var s Settings
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, err
+	}
+	if err := s.Default.check(); err != nil {
+		return nil, err
+	}
+	for name, c := range s.Decks {
+		if err := c.check(); err != nil {
+			return nil, fmt.Errorf("deck %q: %v", name, err)
+		}
+	}
+	return &s, nil
+}
+
+// For returns the Config of the named deck.
+// Rules that the deck's Config enables or disables override Default,
+// as do its nonzero limits.
+func (s *Settings) For(deck string) *Config {
+	c := s.Default
+	d, ok := s.Decks[deck]
+	if !ok {
+		return &c
+	}
+	c.Enable = append(without(c.Enable, d.Disable), d.Enable...)
+	c.Disable = append(without(c.Disable, d.Enable), d.Disable...)
+	if d.MaxTitleLen != 0 {
+		c.MaxTitleLen = d.MaxTitleLen
+	}
+	if d.MaxTextLen != 0 {
+		c.MaxTextLen = d.MaxTextLen
+	}
+	return &c
+}
+
+// contains reports whether a contains s.
+func contains(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns the elements of a that are not in b.
+func without(a, b []string) []string {
+	var c []string
+	for _, v := range a {
+		if !contains(b, v) {
+			c = append(c, v)
+		}
+	}
+	return c
+}
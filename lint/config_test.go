@@ -0,0 +1,72 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSettings(t *testing.T) {
+	s, err := ReadSettings(strings.NewReader(`{
+		"default": {"disable": ["commented-card"], "maxTextLen": 200},
+		"decks": {
+			"Cthulhu Deck": {"enable": ["commented-card"], "disable": ["long-text"], "maxTitleLen": 30}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		deck string
+		want Config
+	}{
+		{"Fish Deck", Config{Disable: []string{"commented-card"}, MaxTextLen: 200}},
+		{
+			"Cthulhu Deck",
+			Config{
+				Enable:      []string{"commented-card"},
+				Disable:     []string{"long-text"},
+				MaxTitleLen: 30,
+				MaxTextLen:  200,
+			},
+		},
+	} {
+		if got := s.For(tt.deck); !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("For(%q): got %+v, want %+v", tt.deck, *got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{
+		`{"default": {"disable": ["no-such-rule"]}}`,
+		`{"decks": {"X": {"enable": ["no-such-rule"]}}}`,
+		`{"default": {"colour": true}}`,
+		`{`,
+	} {
+		if _, err := ReadSettings(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadSettings(%s): got nil error", bad)
+		}
+	}
+}
+
+func TestConfigEnabled(t *testing.T) {
+	off := ruleNamed("commented-card")
+	off.Default = false
+	defer func() { off.Default = true }()
+	on := ruleNamed("missing-type")
+	for _, tt := range []struct {
+		cfg     Config
+		on, off bool
+	}{
+		{Config{}, true, false},
+		{Config{Enable: []string{"commented-card"}}, true, true},
+		{Config{Disable: []string{"missing-type"}}, false, false},
+		{Config{Enable: []string{"missing-type"}, Disable: []string{"missing-type"}}, false, false},
+	} {
+		if got := tt.cfg.enabled(on); got != tt.on {
+			t.Errorf("%+v.enabled(%v): got %v, want %v", tt.cfg, on.Name, got, tt.on)
+		}
+		if got := tt.cfg.enabled(off); got != tt.off {
+			t.Errorf("%+v.enabled(%v): got %v, want %v", tt.cfg, off.Name, got, tt.off)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package lint checks Dvorak decks for common problems.
+//
+// Each problem is reported as a Finding of one of the Rules,
+// which can be enabled and disabled for each deck with a Config.
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+// A Severity is the importance of a Finding.
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(b []byte) error {
+	for _, v := range []Severity{Info, Warning, Error} {
+		if string(b) == v.String() {
+			*s = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", b)
+}
+
+// A Finding is a problem found in a deck.
+type Finding struct {
+	// Rule is the name of the rule that found the problem.
+	Rule string `json:"rule"`
+
+	Severity Severity `json:"severity"`
+
+	// Line is the line number of the problem in the deck's source code.
+	Line int `json:"line"`
+
+	// Card is the ID of the card with the problem,
+	// or 0 if the problem is not with a parsed card.
+	Card int `json:"card,omitempty"`
+
+	// Msg describes the problem.
+	Msg string `json:"msg"`
+}
+
+func (f Finding) String() string {
+	if f.Card == 0 {
+		return fmt.Sprintf("%d: %v: %s [%s]", f.Line, f.Severity, f.Msg, f.Rule)
+	}
+	return fmt.Sprintf("%d: %v: card %d: %s [%s]", f.Line, f.Severity, f.Card, f.Msg, f.Rule)
+}
+
+// A Rule checks a deck for one kind of problem.
+type Rule struct {
+	// Name identifies the rule in Findings and Configs.
+	Name string
+
+	// Severity is the severity of the rule's Findings.
+	Severity Severity
+
+	// Doc describes the problem that the rule finds.
+	Doc string
+
+	// Default indicates whether the rule is enabled by default.
+	Default bool
+
+	check func(r *Rule, d *deck, cfg *Config) []Finding
+}
+
+// A deck is the parsed source code of a deck being checked.
+type deck struct {
+	src   []byte
+	cards []dvorak.Card
+
+	// lines lists the source line number of each card.
+	lines []int
+
+	diags []dvorak.Diagnostic
+}
+
+// finding returns a Finding of r about the card with the given ID.
+func (d *deck) finding(r *Rule, id int, format string, args ...interface{}) Finding {
+	return Finding{
+		Rule:     r.Name,
+		Severity: r.Severity,
+		Line:     d.lines[id-1],
+		Card:     id,
+		Msg:      fmt.Sprintf(format, args...),
+	}
+}
+
+// Lint returns the problems found in the deck with source code src
+// by the rules that cfg enables, ordered by line.
+func Lint(src []byte, cfg *Config) []Finding {
+	if cfg == nil {
+		cfg = new(Config)
+	}
+	d := &deck{
+		src:   src,
+		cards: dvorak.Parse(src),
+		lines: dvorak.CardLines(src),
+		diags: dvorak.Diagnose(src),
+	}
+	var findings []Finding
+	for i := range Rules {
+		r := &Rules[i]
+		if cfg.enabled(r) {
+			findings = append(findings, r.check(r, d, cfg)...)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// JSON returns findings encoded as a JSON array.
+func JSON(findings []Finding) ([]byte, error) {
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return json.MarshalIndent(findings, "", "\t")
+}
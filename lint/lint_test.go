@@ -0,0 +1,97 @@
+package lint
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDeck = `{{card|title=Fish|type=Thing|text=Blub.}}
+{{card|title=fish|type=Action|text=Draw a card.|bgcolor=nope}}
+<!-- {{card|title=|type=Action|text=}} -->
+{{card|title=A Card With A Very Long Title Indeed|text=<b>Bold|image=Missing.png|colour=red}}
+{{card|title=Long|type=Thing|text=` + "Draw a card. Draw a card. Draw a card. Draw a card." + `|image=Found.png}}`
+
+func TestLint(t *testing.T) {
+	cfg := &Config{
+		MaxTextLen: 40,
+		Images:     map[string]bool{"Found.png": true},
+	}
+	want := []Finding{
+		{Rule: "duplicate-title", Severity: Warning, Line: 2, Card: 2, Msg: `duplicate title "fish" (card 1)`},
+		{Rule: "invalid-color", Severity: Error, Line: 2, Card: 2, Msg: `bgcolor: invalid color "nope"`},
+		{Rule: "commented-card", Severity: Info, Line: 3, Msg: "commented-out card template"},
+		{Rule: "missing-type", Severity: Warning, Line: 4, Card: 3, Msg: "missing type"},
+		{Rule: "unresolved-image", Severity: Warning, Line: 4, Card: 3, Msg: `image "Missing.png" not found`},
+		{Rule: "long-title", Severity: Warning, Line: 4, Card: 3, Msg: "title is 36 characters long; consider longtitle"},
+		{Rule: "unknown-param", Severity: Warning, Line: 4, Card: 3, Msg: "colour: unknown parameter"},
+		{Rule: "unbalanced-html", Severity: Error, Line: 4, Card: 3, Msg: "text: unclosed <b>"},
+		{Rule: "long-text", Severity: Warning, Line: 5, Card: 4, Msg: "text is 51 characters long; consider longtext"},
+	}
+	got := Lint([]byte(testDeck), cfg)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint: got\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestLintDisable(t *testing.T) {
+	cfg := &Config{Disable: []string{"commented-card", "empty-text", "missing-type"}}
+	if got := Lint([]byte("<!-- {{card}} -->{{card}}"), cfg); got != nil {
+		t.Errorf("Lint: got %v, want nil", got)
+	}
+	if got := Lint([]byte("{{card|type=Thing}}"), nil); len(got) != 1 || got[0].Rule != "empty-text" {
+		t.Errorf("Lint with nil Config: got %v", got)
+	}
+}
+
+func TestFindingJSON(t *testing.T) {
+	b, err := JSON([]Finding{{Rule: "missing-type", Severity: Warning, Line: 4, Card: 3, Msg: "missing type"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+	{
+		"rule": "missing-type",
+		"severity": "warning",
+		"line": 4,
+		"card": 3,
+		"msg": "missing type"
+	}
+]`
+	if string(b) != want {
+		t.Errorf("JSON: got %s, want %s", b, want)
+	}
+	if b, _ := JSON(nil); string(b) != "[]" {
+		t.Errorf("JSON(nil): got %s, want []", b)
+	}
+
+	var f []Finding
+	if err := json.Unmarshal([]byte(want), &f); err != nil || f[0].Severity != Warning {
+		t.Errorf("Unmarshal: got %v, %v", f, err)
+	}
+}
+
+func TestFindingString(t *testing.T) {
+	for _, tt := range []struct {
+		f    Finding
+		want string
+	}{
+		{Finding{Rule: "missing-type", Severity: Warning, Line: 4, Card: 3, Msg: "missing type"}, "4: warning: card 3: missing type [missing-type]"},
+		{Finding{Rule: "commented-card", Severity: Info, Line: 3, Msg: "commented-out card template"}, "3: info: commented-out card template [commented-card]"},
+	} {
+		if got := tt.f.String(); got != tt.want {
+			t.Errorf("String(): got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestRuleNames(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, r := range Rules {
+		if seen[r.Name] || r.Name != strings.ToLower(r.Name) || r.check == nil {
+			t.Errorf("invalid or duplicate rule %q", r.Name)
+		}
+		seen[r.Name] = true
+	}
+}
@@ -0,0 +1,206 @@
+package lint
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+// Rules lists the available rules.
+var Rules = []Rule{
+	{
+		Name:     "duplicate-title",
+		Severity: Warning,
+		Doc:      "a card has the same title as an earlier card",
+		Default:  true,
+		check:    checkDuplicateTitles,
+	},
+	{
+		Name:     "empty-text",
+		Severity: Warning,
+		Doc:      "a card has no rule text",
+		Default:  true,
+		check:    checkEmptyText,
+	},
+	{
+		Name:     "missing-type",
+		Severity: Warning,
+		Doc:      "a card has no type",
+		Default:  true,
+		check:    checkMissingType,
+	},
+	{
+		Name:     "invalid-color",
+		Severity: Error,
+		Doc:      "a card's bgcolor or imgback is not a valid color",
+		Default:  true,
+		check:    diagnostics(dvorak.InvalidColor),
+	},
+	{
+		Name:     "unresolved-image",
+		Severity: Warning,
+		Doc:      "a card's image cannot be found on the wiki; requires Config.Images",
+		Default:  true,
+		check:    checkImages,
+	},
+	{
+		Name:     "long-text",
+		Severity: Warning,
+		Doc:      "a card's rule text is too long for the standard text area and longtext is not set",
+		Default:  true,
+		check:    checkLongText,
+	},
+	{
+		Name:     "long-title",
+		Severity: Warning,
+		Doc:      "a card's title is too long for the standard header and longtitle is not set",
+		Default:  true,
+		check:    checkLongTitle,
+	},
+	{
+		Name:     "unknown-param",
+		Severity: Warning,
+		Doc:      "a card template has a parameter that the template does not use",
+		Default:  true,
+		check:    diagnostics(dvorak.UnknownParam),
+	},
+	{
+		Name:     "unbalanced-html",
+		Severity: Error,
+		Doc:      "a card template parameter has unclosed or unexpected HTML tags",
+		Default:  true,
+		check:    diagnostics(dvorak.UnbalancedHTML),
+	},
+	{
+		Name:     "commented-card",
+		Severity: Info,
+		Doc:      "an HTML comment contains a card template, such as a placeholder for a new card",
+		Default:  true,
+		check:    checkCommentedCards,
+	},
+}
+
+func checkDuplicateTitles(r *Rule, d *deck, cfg *Config) []Finding {
+	var findings []Finding
+	first := make(map[string]int)
+	for _, c := range d.cards {
+		title := strings.ToLower(c.TitleText())
+		if title == "" {
+			continue
+		}
+		if id, ok := first[title]; ok {
+			findings = append(findings, d.finding(r, c.ID, "duplicate title %q (card %d)", c.TitleText(), id))
+			continue
+		}
+		first[title] = c.ID
+	}
+	return findings
+}
+
+func checkEmptyText(r *Rule, d *deck, cfg *Config) []Finding {
+	var findings []Finding
+	for _, c := range d.cards {
+		if c.PlainText() == "" {
+			findings = append(findings, d.finding(r, c.ID, "empty text"))
+		}
+	}
+	return findings
+}
+
+func checkMissingType(r *Rule, d *deck, cfg *Config) []Finding {
+	var findings []Finding
+	for _, c := range d.cards {
+		if c.TypeText() == "" {
+			findings = append(findings, d.finding(r, c.ID, "missing type"))
+		}
+	}
+	return findings
+}
+
+func checkImages(r *Rule, d *deck, cfg *Config) []Finding {
+	if cfg.Images == nil {
+		return nil
+	}
+	var findings []Finding
+	for _, c := range d.cards {
+		if c.Image != "" && !cfg.Images[c.Image] {
+			findings = append(findings, d.finding(r, c.ID, "image %q not found", c.Image))
+		}
+	}
+	return findings
+}
+
+func checkLongText(r *Rule, d *deck, cfg *Config) []Finding {
+	var findings []Finding
+	for _, c := range d.cards {
+		if n := utf8.RuneCountInString(c.PlainText()); !c.LongText && n > cfg.maxTextLen() {
+			findings = append(findings, d.finding(r, c.ID, "text is %d characters long; consider longtext", n))
+		}
+	}
+	return findings
+}
+
+func checkLongTitle(r *Rule, d *deck, cfg *Config) []Finding {
+	var findings []Finding
+	for _, c := range d.cards {
+		if n := utf8.RuneCountInString(c.TitleText()); !c.LongTitle && n > cfg.maxTitleLen() {
+			findings = append(findings, d.finding(r, c.ID, "title is %d characters long; consider longtitle", n))
+		}
+	}
+	return findings
+}
+
+// diagnostics returns a check function that reports the dvorak.Diagnostics
+// of the given kind.
+func diagnostics(kind dvorak.DiagnosticKind) func(r *Rule, d *deck, cfg *Config) []Finding {
+	return func(r *Rule, d *deck, cfg *Config) []Finding {
+		var findings []Finding
+		for _, diag := range d.diags {
+			if diag.Kind != kind {
+				continue
+			}
+			msg := diag.Msg
+			if diag.Param != "" {
+				msg = diag.Param + ": " + msg
+			}
+			findings = append(findings, Finding{
+				Rule:     r.Name,
+				Severity: r.Severity,
+				Line:     diag.Line,
+				Card:     diag.Card,
+				Msg:      msg,
+			})
+		}
+		return findings
+	}
+}
+
+// comment matches an HTML comment.
+var comment = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// cardTemplate matches the beginning of a card template.
+var cardTemplate = regexp.MustCompile(`\{\{\s*(?:[Tt]emplate:)?[Cc]ard\s*[|}]`)
+
+func checkCommentedCards(r *Rule, d *deck, cfg *Config) []Finding {
+	var findings []Finding
+	for _, loc := range comment.FindAllIndex(d.src, -1) {
+		n := len(cardTemplate.FindAllIndex(d.src[loc[0]:loc[1]], -1))
+		if n == 0 {
+			continue
+		}
+		msg := "commented-out card template"
+		if n > 1 {
+			msg = "commented-out card templates"
+		}
+		findings = append(findings, Finding{
+			Rule:     r.Name,
+			Severity: r.Severity,
+			Line:     1 + bytes.Count(d.src[:loc[0]], []byte("\n")),
+			Msg:      msg,
+		})
+	}
+	return findings
+}
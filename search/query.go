@@ -0,0 +1,73 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A clause is a term or phrase that a card must contain,
+// or must not contain if negated.
+type clause struct {
+	// field is the field to search, or anyField.
+	field field
+
+	// terms are the stemmed words of the phrase.
+	terms []string
+
+	negate bool
+}
+
+// parseQuery parses a query into clauses.
+//
+// A query is a sequence of words and double-quoted phrases, each of which
+// may be preceded by a field name and a colon to restrict it to that field,
+// and by "-" to exclude matching cards. A phrase, or a word with a field
+// name or "-", must contain a letter or digit. Other words without
+// letters or digits, such as "&", are ignored.
+func parseQuery(q string) ([]clause, error) {
+	var clauses []clause
+	for q = strings.TrimSpace(q); q != ""; q = strings.TrimSpace(q) {
+		var c clause
+		start := q
+		if q[0] == '-' {
+			c.negate = true
+			q = q[1:]
+		}
+		if i := strings.IndexFunc(q, func(r rune) bool { return r == ':' || r == '"' || unicode.IsSpace(r) }); i > 0 && q[i] == ':' {
+			f, ok := fieldNames[strings.ToLower(q[:i])]
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q", q[:i])
+			}
+			c.field = f
+			q = q[i+1:]
+		} else {
+			c.field = anyField
+		}
+
+		var text string
+		phrase := strings.HasPrefix(q, `"`)
+		if phrase {
+			end := strings.Index(q[1:], `"`)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated phrase %s", q)
+			}
+			text, q = q[1:end+1], q[end+2:]
+		} else {
+			end := strings.IndexFunc(q, unicode.IsSpace)
+			if end == -1 {
+				end = len(q)
+			}
+			text, q = q[:end], q[end:]
+		}
+		c.terms = tokenize(text)
+		if len(c.terms) == 0 {
+			if phrase || c.negate || c.field != anyField {
+				return nil, fmt.Errorf("empty search term %q", strings.TrimSpace(start[:len(start)-len(q)]))
+			}
+			continue
+		}
+		clauses = append(clauses, c)
+	}
+	return clauses, nil
+}
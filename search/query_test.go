@@ -0,0 +1,65 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	for _, tt := range []struct {
+		q     string
+		want  []clause
+		isErr bool
+	}{
+		{"", nil, false},
+		{"  ", nil, false},
+		{"fish", []clause{{field: anyField, terms: []string{"fish"}}}, false},
+		{
+			"steals Thing",
+			[]clause{
+				{field: anyField, terms: []string{"steal"}},
+				{field: anyField, terms: []string{"thing"}},
+			},
+			false,
+		},
+		{
+			`"draw a card"`,
+			[]clause{{field: anyField, terms: []string{"draw", "a", "card"}}},
+			false,
+		},
+		{
+			`type:thing creator:Binarius deck:"Cthulhu Deck" -text:destroy`,
+			[]clause{
+				{field: typeField, terms: []string{"thing"}},
+				{field: creatorField, terms: []string{"binarius"}},
+				{field: deckField, terms: []string{"cthulhu", "deck"}},
+				{field: textField, terms: []string{"destroy"}, negate: true},
+			},
+			false,
+		},
+		{`TITLE:"fish"`, []clause{{field: titleField, terms: []string{"fish"}}}, false},
+		{"-", nil, true},
+		{"type:", nil, true},
+		{"fish -text:!", nil, true},
+		{`""`, nil, true},
+		{"&", nil, false},
+		{
+			"fish & chips",
+			[]clause{
+				{field: anyField, terms: []string{"fish"}},
+				{field: anyField, terms: []string{"chip"}},
+			},
+			false,
+		},
+		{"color:red", nil, true},
+		{`"draw a card`, nil, true},
+	} {
+		got, err := parseQuery(tt.q)
+		if (err != nil) != tt.isErr {
+			t.Errorf("parseQuery(%q): error %v, want error=%v", tt.q, err, tt.isErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseQuery(%q): got %+v, want %+v", tt.q, got, tt.want)
+		}
+	}
+}
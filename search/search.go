@@ -0,0 +1,206 @@
+// Package search implements full-text search of Dvorak cards.
+//
+// A query is a sequence of words and double-quoted phrases, all of which
+// a card must contain. Words are matched without regard to case or common
+// English inflections, so "destroys" matches "destroyed". A word or phrase
+// preceded by a field name and a colon must appear in that field, as in
+// type:thing, creator:Binarius, or deck:"Cthulhu", and one preceded by "-"
+// must not appear. The fields are title, type, text, flavor, creator,
+// and deck. Words without a field name match any field except deck.
+//
+// Results are ranked by the number of matches in each field, weighted by
+// the field and by the rarity of the words among all indexed cards.
+package search
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+// A field is a searchable part of a card.
+type field int
+
+const (
+	titleField field = iota
+	typeField
+	textField
+	flavorField
+	creatorField
+	deckField
+	numFields
+
+	// anyField matches any field except deckField.
+	anyField field = -1
+)
+
+// fieldNames maps the field names of queries to fields.
+var fieldNames = map[string]field{
+	"title":      titleField,
+	"type":       typeField,
+	"text":       textField,
+	"flavor":     flavorField,
+	"flavortext": flavorField,
+	"creator":    creatorField,
+	"deck":       deckField,
+}
+
+// weights are the relative weights of matches in each field.
+var weights = [numFields]float64{
+	titleField:   4,
+	typeField:    2,
+	textField:    1,
+	flavorField:  0.5,
+	creatorField: 1,
+	deckField:    0.5,
+}
+
+// An Index is an in-memory index of cards.
+// The zero value is an empty Index ready to use.
+type Index struct {
+	docs []doc
+
+	// postings maps each term to the positions at which it appears
+	// in each field of each card.
+	postings map[string]map[docField][]int
+}
+
+// A doc is an indexed card.
+type doc struct {
+	deck string
+	card dvorak.Card
+}
+
+// A docField identifies a field of an indexed card.
+type docField struct {
+	doc   int
+	field field
+}
+
+// A Result is a card that matches a query.
+type Result struct {
+	// Deck is the name of the card's deck.
+	Deck string
+
+	Card dvorak.Card
+
+	// Score is the relevance of the card to the query.
+	// Higher scores are more relevant.
+	Score float64
+}
+
+// Add adds the cards of d to the index.
+func (ix *Index) Add(d dvorak.Deck) {
+	if ix.postings == nil {
+		ix.postings = make(map[string]map[docField][]int)
+	}
+	for _, c := range d.Cards {
+		n := len(ix.docs)
+		ix.docs = append(ix.docs, doc{deck: d.Name, card: c})
+		for f, s := range [numFields]string{
+			titleField:   c.TitleText(),
+			typeField:    c.TypeText(),
+			textField:    c.PlainText(),
+			flavorField:  c.FlavorPlainText(),
+			creatorField: c.CreatorText(),
+			deckField:    d.Name,
+		} {
+			for pos, term := range tokenize(s) {
+				p := ix.postings[term]
+				if p == nil {
+					p = make(map[docField][]int)
+					ix.postings[term] = p
+				}
+				key := docField{n, field(f)}
+				p[key] = append(p[key], pos)
+			}
+		}
+	}
+}
+
+// Len returns the number of cards in the index.
+func (ix *Index) Len() int { return len(ix.docs) }
+
+// Search returns the cards that match query in order of decreasing relevance.
+// Cards of equal relevance are returned in the order they were added.
+// An empty query matches every card.
+func (ix *Index) Search(query string) ([]Result, error) {
+	clauses, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	idfs := make([]float64, len(clauses))
+	for i, c := range clauses {
+		idfs[i] = ix.idf(c.terms[0])
+	}
+	var results []Result
+	for n, d := range ix.docs {
+		score, ok := ix.score(n, clauses, idfs)
+		if ok {
+			results = append(results, Result{Deck: d.deck, Card: d.card, Score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results, nil
+}
+
+// score returns the relevance of the nth card to clauses,
+// given the inverse document frequency of the first term of each,
+// and reports whether the card matches them.
+func (ix *Index) score(n int, clauses []clause, idfs []float64) (float64, bool) {
+	var score float64
+	for i, c := range clauses {
+		var s float64
+		for f := field(0); f < numFields; f++ {
+			if c.field == f || c.field == anyField && f != deckField {
+				s += weights[f] * float64(ix.count(c.terms, docField{n, f}))
+			}
+		}
+		if (s > 0) == c.negate {
+			return 0, false
+		}
+		score += s * idfs[i]
+	}
+	return score, true
+}
+
+// count returns the number of occurrences of the phrase terms in df.
+func (ix *Index) count(terms []string, df docField) int {
+	var k int
+	for _, pos := range ix.postings[terms[0]][df] {
+		if ix.phraseAt(terms[1:], df, pos+1) {
+			k++
+		}
+	}
+	return k
+}
+
+// phraseAt reports whether the phrase terms appears in df at pos.
+func (ix *Index) phraseAt(terms []string, df docField, pos int) bool {
+	for i, t := range terms {
+		found := false
+		for _, p := range ix.postings[t][df] {
+			if p == pos+i {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// idf returns the inverse document frequency of term.
+func (ix *Index) idf(term string) float64 {
+	docs := make(map[int]bool)
+	for df := range ix.postings[term] {
+		docs[df.doc] = true
+	}
+	return math.Log(1 + float64(len(ix.docs))/float64(1+len(docs)))
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dkmccandless/dvorak"
+)
+
+func newTestIndex() *Index {
+	var ix Index
+	ix.Add(dvorak.Deck{
+		Name: "Cthulhu Deck",
+		Cards: dvorak.Parse([]byte(`
+			{{card|title=Elder Sign|type=Thing|text=Cthulhu cannot destroy Things.|creator=Binarius}}
+			{{card|title=Madness|type=Action|text=Steal target Thing. Draw a card.|creator=Binarius}}
+			{{card|title=Cthulhu|type=Thing - Elder God|text='''Action:''' Destroy target Thing.|creator=Ghoul}}
+		`)),
+	})
+	ix.Add(dvorak.Deck{
+		Name: "Fish Deck",
+		Cards: dvorak.Parse([]byte(`
+			{{card|title=Fishing Rod|type=Action|text=Gain control of a fish.|flavortext=It stole my Thing!|creator=Angler}}
+			{{card|title=Thief|type=Thing|text='''Action:''' Steal a Thing that is a fish.}}
+		`)),
+	})
+	return &ix
+}
+
+func TestSearch(t *testing.T) {
+	ix := newTestIndex()
+	if n := ix.Len(); n != 5 {
+		t.Fatalf("Len: got %d, want 5", n)
+	}
+	for _, tt := range []struct {
+		q    string
+		want []string
+	}{
+		{"", []string{"Elder Sign", "Madness", "Cthulhu", "Fishing Rod", "Thief"}},
+		{"nonexistent", nil},
+		{"cthulhu", []string{"Cthulhu", "Elder Sign"}},
+		{"deck:cthulhu", []string{"Elder Sign", "Madness", "Cthulhu"}},
+		{"steals a thing", []string{"Thief", "Madness"}},
+		{"destroyed", []string{"Elder Sign", "Cthulhu"}},
+		{`"destroy target thing"`, []string{"Cthulhu"}},
+		{"type:thing", []string{"Elder Sign", "Cthulhu", "Thief"}},
+		{"type:thing -title:cthulhu", []string{"Elder Sign", "Thief"}},
+		{"creator:Binarius", []string{"Elder Sign", "Madness"}},
+		{`deck:"Fish" fish`, []string{"Fishing Rod", "Thief"}},
+		{"type:elder", []string{"Cthulhu"}},
+		{"flavor:stole", []string{"Fishing Rod"}},
+	} {
+		results, err := ix.Search(tt.q)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.q, err)
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, r.Card.TitleText())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q): got %q, want %q", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestSearchInflections(t *testing.T) {
+	var ix Index
+	ix.Add(dvorak.Deck{
+		Name:  "Physics Deck",
+		Cards: dvorak.Parse([]byte("{{card|title=String Theory|type=Thing|text=Copies of this are destroyed.}}")),
+	})
+	for _, q := range []string{"strings", "string", "theories", "copied", "destroying", `"string theories"`} {
+		results, err := ix.Search(q)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", q, err)
+		}
+		if len(results) != 1 {
+			t.Errorf("Search(%q): got %d results, want 1", q, len(results))
+		}
+	}
+}
+
+func TestSearchResult(t *testing.T) {
+	results, err := newTestIndex().Search("rod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Deck != "Fish Deck" || results[0].Card.ID != 1 || results[0].Score <= 0 {
+		t.Errorf("Search(rod): got %+v", results)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	for _, q := range []string{"color:red", "-", "type:"} {
+		if _, err := newTestIndex().Search(q); err == nil {
+			t.Errorf("Search(%q): got nil error", q)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenize returns the lowercase, stemmed words of s in order.
+func tokenize(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = stem(w)
+	}
+	return words
+}
+
+// stem returns the stem of the lowercase word w, removing common English
+// inflectional suffixes so that, for example, "destroys", "destroyed",
+// and "destroying" all have the stem "destroy".
+//
+// Suffixes are removed until none remains, so that the stem of a stem is
+// itself. Otherwise a word and its plural could have different stems:
+// "strings" would stem to "string", but "string" to "str".
+func stem(w string) string {
+	for {
+		s := stemSuffix(w)
+		if s == w {
+			return s
+		}
+		w = s
+	}
+}
+
+// stemSuffix returns w without one inflectional suffix,
+// or w if it has none.
+func stemSuffix(w string) string {
+	if len(w) <= 3 {
+		return w
+	}
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "shes"),
+		strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "xes"),
+		strings.HasSuffix(w, "zes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		return w[:len(w)-1]
+	case strings.HasSuffix(w, "ing") && len(w) > 5:
+		return undouble(w[:len(w)-3])
+	case strings.HasSuffix(w, "ed") && len(w) > 4:
+		if strings.HasSuffix(w, "ied") {
+			return w[:len(w)-3] + "y"
+		}
+		return undouble(w[:len(w)-2])
+	case strings.HasSuffix(w, "ly") && len(w) > 4:
+		return w[:len(w)-2]
+	}
+	return w
+}
+
+// undouble removes the last letter of w if it doubles the one before it,
+// as in "stopp" from "stopped", unless the letter is commonly doubled.
+func undouble(w string) string {
+	n := len(w)
+	if n >= 2 && w[n-1] == w[n-2] && !strings.ContainsRune("lsz", rune(w[n-1])) {
+		return w[:n-1]
+	}
+	return w
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	for _, tt := range []struct{ w, want string }{
+		{"a", "a"},
+		{"card", "card"},
+		{"cards", "card"},
+		{"things", "thing"},
+		{"thing", "thing"},
+		{"destroy", "destroy"},
+		{"destroys", "destroy"},
+		{"destroyed", "destroy"},
+		{"destroying", "destroy"},
+		{"steals", "steal"},
+		{"stopped", "stop"},
+		{"called", "call"},
+		{"copies", "copy"},
+		{"copied", "copy"},
+		{"matches", "match"},
+		{"boxes", "box"},
+		{"class", "class"},
+		{"octopus", "octopus"},
+		{"randomly", "random"},
+		{"string", "str"},
+		{"strings", "str"},
+		{"theory", "theory"},
+		{"theories", "theory"},
+		{"blessed", "bless"},
+		{"dressings", "dress"},
+		{"nothings", "noth"},
+	} {
+		if got := stem(tt.w); got != tt.want {
+			t.Errorf("stem(%q): got %q, want %q", tt.w, got, tt.want)
+		}
+		if got := stem(stem(tt.w)); got != stem(tt.w) {
+			t.Errorf("stem(stem(%q)): got %q, want %q", tt.w, got, stem(tt.w))
+		}
+	}
+}
+
+func TestStemInflections(t *testing.T) {
+	// Each group lists inflections of a word, which must share a stem.
+	for _, words := range [][]string{
+		{"string", "strings"},
+		{"theory", "theories"},
+		{"destroy", "destroys", "destroyed", "destroying"},
+		{"stop", "stops", "stopped", "stopping"},
+		{"copy", "copies", "copied"},
+		{"match", "matches", "matched", "matching"},
+		{"thing", "things"},
+		{"dressing", "dressings"},
+		{"card", "cards"},
+	} {
+		want := stem(words[0])
+		for _, w := range words[1:] {
+			if got := stem(w); got != want {
+				t.Errorf("stem(%q) = %q, want %q, the stem of %q", w, got, want, words[0])
+			}
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want []string
+	}{
+		{"", []string{}},
+		{"Destroy target Thing.", []string{"destroy", "target", "thing"}},
+		{"Gain control of 2 fish-cards!", []string{"gain", "control", "of", "2", "fish", "card"}},
+	} {
+		if got := tokenize(tt.s); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q): got %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
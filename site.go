@@ -0,0 +1,219 @@
+package dvorak
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A Site is a MediaWiki wiki that hosts Dvorak decks,
+// such as the Dvorak wiki or a mirror of it.
+type Site struct {
+	// URL is the base URL of the wiki, such as "https://dvorakgame.co.uk".
+	// The Client's requests are sent to its scheme and host.
+	URL string
+
+	// IndexPath and APIPath are the paths of the wiki's index.php and
+	// api.php relative to URL. If empty, "/index.php" and "/api.php"
+	// are used.
+	IndexPath string
+	APIPath   string
+
+	// Hosts are the names of other hosts whose page URLs are accepted as
+	// pages of the wiki, such as a former host name. The host of URL is
+	// always accepted. Names may include a port.
+	Hosts []string
+
+	// StripWWW indicates that the wiki reports file URLs on a "www."
+	// host that should be requested without it, because the wiki's TLS
+	// certificate does not cover that host.
+	StripWWW bool
+}
+
+// DefaultSite is the Dvorak wiki.
+var DefaultSite = &Site{
+	URL:      "https://dvorakgame.co.uk",
+	StripWWW: true,
+}
+
+// site returns the Site of c.
+func (c *Client) site() *Site {
+	if c.Site == nil {
+		return DefaultSite
+	}
+	return c.Site
+}
+
+// IndexURL returns the URL of the wiki's index.php.
+func (s *Site) IndexURL() string {
+	p := s.IndexPath
+	if p == "" {
+		p = "/index.php"
+	}
+	return strings.TrimSuffix(s.URL, "/") + p
+}
+
+// APIURL returns the URL of the wiki's api.php.
+func (s *Site) APIURL() string {
+	p := s.APIPath
+	if p == "" {
+		p = "/api.php"
+	}
+	return strings.TrimSuffix(s.URL, "/") + p
+}
+
+// PageURL returns the URL of the wiki page with the given title.
+func (s *Site) PageURL(title string) string {
+	u, err := url.Parse(s.IndexURL())
+	if err != nil {
+		return s.IndexURL() + "/" + url.PathEscape(strings.ReplaceAll(title, " ", "_"))
+	}
+	u.Path += "/" + strings.ReplaceAll(title, " ", "_")
+	return u.String()
+}
+
+// checkHost returns an error if u is not on a host of s.
+// If it is, checkHost sets the scheme and host of u to those of s.URL.
+func (s *Site) checkHost(u *url.URL) error {
+	base, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("site URL: %v", err)
+	}
+	ok := onHost(u, base.Host)
+	for _, h := range s.Hosts {
+		ok = ok || onHost(u, h)
+	}
+	if !ok {
+		return fmt.Errorf("%w %q", ErrInvalidHost, u.Hostname())
+	}
+	u.Scheme, u.Host = base.Scheme, base.Host
+	return nil
+}
+
+// onHost reports whether u is on host, which may include a port,
+// or on its "www." or mobile "m." subdomain.
+func onHost(u *url.URL, host string) bool {
+	got := u.Hostname()
+	if strings.Contains(host, ":") {
+		got = u.Host
+	}
+	got, host = strings.ToLower(got), strings.ToLower(host)
+	return got == host || got == "www."+host || got == "m."+host
+}
+
+// fileURL returns the URL at which to request a file that the wiki
+// reports at rawURL.
+func (s *Site) fileURL(rawURL string) string {
+	if !s.StripWWW {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.HasPrefix(strings.ToLower(u.Host), "www.") {
+		return rawURL
+	}
+	u.Host = u.Host[len("www."):]
+	return u.String()
+}
+
+// A PageRef identifies a page of a wiki, or a revision of a page.
+type PageRef struct {
+	// Title is the page's title in canonical form, with spaces instead
+	// of underscores and an initial capital. It is empty if the page
+	// is identified only by RevID.
+	Title string
+
+	// RevID is the ID of a revision of the page, or 0 for its latest revision.
+	RevID int64
+}
+
+// ParseURL returns a reference to the page of s at rawURL.
+// rawURL must be on a host of s or its "www." or mobile "m." subdomain.
+// The page is named by the title query parameter or by the path
+// following the Site's index.php or "/wiki", and may be percent-encoded.
+// An oldid query parameter specifies a revision of the page,
+// and any fragment is ignored.
+//
+// For example, these URLs all refer to the page "Cthulhu Deck":
+//
+//	https://dvorakgame.co.uk/index.php/Cthulhu_Deck
+//	https://dvorakgame.co.uk/index.php?title=Cthulhu_Deck&action=edit
+//	https://dvorakgame.co.uk/wiki/Cthulhu%20Deck#Cards
+//	https://m.dvorakgame.co.uk/index.php/cthulhu_Deck
+func (s *Site) ParseURL(rawURL string) (PageRef, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return PageRef{}, err
+	}
+	if err := s.checkHost(u); err != nil {
+		return PageRef{}, err
+	}
+
+	var ref PageRef
+	q := u.Query()
+	if v := q.Get("oldid"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return PageRef{}, fmt.Errorf("%v: invalid oldid %q", rawURL, v)
+		}
+		ref.RevID = id
+	}
+
+	title := q.Get("title")
+	if title == "" {
+		base, err := url.Parse(s.URL)
+		if err != nil {
+			return PageRef{}, fmt.Errorf("site URL: %v", err)
+		}
+		index, err := url.Parse(s.IndexURL())
+		if err != nil {
+			return PageRef{}, fmt.Errorf("site URL: %v", err)
+		}
+		for _, prefix := range []string{index.Path + "/", strings.TrimSuffix(base.Path, "/") + "/wiki/"} {
+			if strings.HasPrefix(u.Path, prefix) {
+				title = u.Path[len(prefix):]
+				break
+			}
+		}
+	}
+	ref.Title = canonicalTitle(title)
+	if ref.Title == "" && ref.RevID == 0 {
+		return PageRef{}, fmt.Errorf("%v: no page title", rawURL)
+	}
+	return ref, nil
+}
+
+// pageTitle returns the title of the page of s at rawURL,
+// ignoring any revision.
+func (s *Site) pageTitle(rawURL string) (string, error) {
+	ref, err := s.ParseURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if ref.Title == "" {
+		return "", fmt.Errorf("%v: no page title", rawURL)
+	}
+	return ref.Title, nil
+}
+
+// canonicalTitle returns title in canonical form, without any fragment.
+func canonicalTitle(title string) string {
+	if i := strings.Index(title, "#"); i >= 0 {
+		title = title[:i]
+	}
+	title = strings.Join(strings.Fields(strings.ReplaceAll(title, "_", " ")), " ")
+	for i, r := range title {
+		return strings.ToUpper(string(r)) + title[i+len(string(r)):]
+	}
+	return title
+}
+
+// rawURL returns the URL of the raw source code of the page
+// with the given title.
+func (s *Site) rawURL(title string) string {
+	v := url.Values{
+		"title":  {strings.ReplaceAll(title, " ", "_")},
+		"action": {"raw"},
+	}
+	return s.IndexURL() + "?" + v.Encode()
+}
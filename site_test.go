@@ -0,0 +1,117 @@
+package dvorak
+
+import (
+	"testing"
+
+	"github.com/dkmccandless/dvorak/dvoraktest"
+)
+
+// mirror is a wiki with nonstandard paths that has moved from another host.
+var mirror = &Site{
+	URL:       "http://mirror.example:8080/wiki/",
+	IndexPath: "/w/index.php",
+	APIPath:   "/w/api.php",
+	Hosts:     []string{"old.example"},
+}
+
+func TestSiteURLs(t *testing.T) {
+	for _, tt := range []struct {
+		site             *Site
+		index, api, page string
+	}{
+		{
+			DefaultSite,
+			"https://dvorakgame.co.uk/index.php",
+			"https://dvorakgame.co.uk/api.php",
+			"https://dvorakgame.co.uk/index.php/Cthulhu_Deck/Cards_1-100",
+		},
+		{
+			mirror,
+			"http://mirror.example:8080/wiki/w/index.php",
+			"http://mirror.example:8080/wiki/w/api.php",
+			"http://mirror.example:8080/wiki/w/index.php/Cthulhu_Deck/Cards_1-100",
+		},
+	} {
+		if got := tt.site.IndexURL(); got != tt.index {
+			t.Errorf("IndexURL() = %q, want %q", got, tt.index)
+		}
+		if got := tt.site.APIURL(); got != tt.api {
+			t.Errorf("APIURL() = %q, want %q", got, tt.api)
+		}
+		if got := tt.site.PageURL("Cthulhu Deck/Cards 1-100"); got != tt.page {
+			t.Errorf("PageURL() = %q, want %q", got, tt.page)
+		}
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	for _, tt := range []struct {
+		site *Site
+		url  string
+		want PageRef
+		ok   bool
+	}{
+		{DefaultSite, "https://dvorakgame.co.uk/index.php/Cthulhu_Deck", PageRef{Title: "Cthulhu Deck"}, true},
+		{DefaultSite, "http://DvorakGame.co.uk/index.php/Deck/Sub", PageRef{Title: "Deck/Sub"}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php/Pirate_Deck?action=raw", PageRef{Title: "Pirate Deck"}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php?title=Cthulhu_Deck&action=edit", PageRef{Title: "Cthulhu Deck"}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php?title=Cthulhu+Deck%2FCards_1-100", PageRef{Title: "Cthulhu Deck/Cards 1-100"}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/wiki/Cthulhu_Deck", PageRef{Title: "Cthulhu Deck"}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/wiki/Caf%C3%A9%20Deck", PageRef{Title: "Café Deck"}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php/Cthulhu_Deck#Cards", PageRef{Title: "Cthulhu Deck"}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php?title=Cthulhu_Deck%23Cards", PageRef{Title: "Cthulhu Deck"}, true},
+		{DefaultSite, "https://m.dvorakgame.co.uk/index.php/cthulhu__Deck", PageRef{Title: "Cthulhu Deck"}, true},
+		{DefaultSite, "https://www.dvorakgame.co.uk/index.php/Cthulhu_Deck", PageRef{Title: "Cthulhu Deck"}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php?title=Cthulhu_Deck&oldid=3", PageRef{Title: "Cthulhu Deck", RevID: 3}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php?oldid=3", PageRef{RevID: 3}, true},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php?title=Cthulhu_Deck&oldid=prev", PageRef{}, false},
+		{DefaultSite, "https://example.com/index.php/Cthulhu_Deck", PageRef{}, false},
+		{DefaultSite, "https://mm.dvorakgame.co.uk/index.php/Cthulhu_Deck", PageRef{}, false},
+		{DefaultSite, "https://dvorakgame.co.uk/", PageRef{}, false},
+		{DefaultSite, "https://dvorakgame.co.uk/index.php/", PageRef{}, false},
+		{DefaultSite, "https://dvorakgame.co.uk/images/Cthulhu_Deck", PageRef{}, false},
+		{mirror, "http://mirror.example:8080/wiki/w/index.php/Cthulhu_Deck", PageRef{Title: "Cthulhu Deck"}, true},
+		{mirror, "http://mirror.example:8080/wiki/wiki/Cthulhu_Deck", PageRef{Title: "Cthulhu Deck"}, true},
+		{mirror, "https://old.example/wiki/w/index.php/Cthulhu_Deck", PageRef{Title: "Cthulhu Deck"}, true},
+		{mirror, "http://mirror.example:9090/wiki/w/index.php/Cthulhu_Deck", PageRef{}, false},
+		{mirror, "http://mirror.example:8080/index.php/Cthulhu_Deck", PageRef{}, false},
+		{mirror, "https://dvorakgame.co.uk/index.php/Cthulhu_Deck", PageRef{}, false},
+	} {
+		got, err := tt.site.ParseURL(tt.url)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("ParseURL(%q): got %+v, %v; want %+v, ok %v", tt.url, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	const u = "https://www.dvorakgame.co.uk/images/a/ab/www.png"
+	if got, want := DefaultSite.fileURL(u), "https://dvorakgame.co.uk/images/a/ab/www.png"; got != want {
+		t.Errorf("DefaultSite.fileURL(%q) = %q, want %q", u, got, want)
+	}
+	if got := mirror.fileURL(u); got != u {
+		t.Errorf("mirror.fileURL(%q) = %q, want it unchanged", u, got)
+	}
+}
+
+func TestGetMirror(t *testing.T) {
+	s := dvoraktest.NewServer()
+	defer s.Close()
+	s.SetPage("Test Deck", "{{card|title=A}}")
+	c := &Client{
+		Site:       &Site{URL: "https://staging.example", Hosts: []string{"dvorakgame.co.uk"}},
+		HTTPClient: s.Client(),
+	}
+	for _, u := range []string{
+		"https://staging.example/index.php/Test_Deck",
+		"https://dvorakgame.co.uk/index.php/Test_Deck",
+	} {
+		b, err := c.Get(u)
+		if err != nil || len(Parse(b)) != 1 {
+			t.Errorf("Get(%q): got %q, %v; want one card", u, b, err)
+		}
+	}
+	if _, err := c.Get("https://example.com/index.php/Test_Deck"); err == nil {
+		t.Errorf("Get from another host: got nil error")
+	}
+}
@@ -0,0 +1,294 @@
+package dvorak
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DeckStats holds aggregate statistics of a deck.
+type DeckStats struct {
+	// Name is the deck's name.
+	Name string `json:"name"`
+
+	// Cards is the number of cards in the deck.
+	Cards int `json:"cards"`
+
+	// Actions and Things are the numbers of cards with each supertype.
+	// A card with both supertypes is counted in both.
+	Actions int `json:"actions"`
+	Things  int `json:"things"`
+
+	// Other is the number of cards with neither supertype.
+	Other int `json:"other"`
+
+	// WithImages is the number of cards with an image.
+	WithImages int `json:"withImages"`
+
+	// AvgTextLen is the mean length of the cards' rule text in characters.
+	AvgTextLen float64 `json:"avgTextLen"`
+
+	// Creators counts the cards of each creator in decreasing order.
+	// Cards without a creator are not counted.
+	Creators []Count `json:"creators"`
+
+	// Subtypes counts the cards of each subtype in decreasing order.
+	Subtypes []Count `json:"subtypes"`
+
+	// Keywords counts the cards with each rule text keyword
+	// in decreasing order.
+	Keywords []Count `json:"keywords"`
+
+	// TextLengths is a histogram of the lengths of the cards' rule text.
+	TextLengths Histogram `json:"textLengths"`
+}
+
+// A Count is the number of cards with a property.
+type Count struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+}
+
+// A Histogram counts values in buckets of equal width.
+type Histogram struct {
+	// Width is the width of each bucket.
+	Width int `json:"width"`
+
+	// Counts is the number of values in each bucket.
+	// Bucket i counts values v with i*Width <= v < (i+1)*Width.
+	Counts []int `json:"counts"`
+}
+
+// add adds v to h.
+func (h *Histogram) add(v int) {
+	i := v / h.Width
+	for len(h.Counts) <= i {
+		h.Counts = append(h.Counts, 0)
+	}
+	h.Counts[i]++
+}
+
+// textLenWidth is the bucket width of DeckStats.TextLengths.
+const textLenWidth = 50
+
+// Stats returns the statistics of d.
+func Stats(d Deck) DeckStats {
+	s := DeckStats{
+		Name:        d.Name,
+		Cards:       len(d.Cards),
+		TextLengths: Histogram{Width: textLenWidth},
+	}
+	creators := make(map[string]int)
+	subtypes := make(map[string]int)
+	keywords := make(map[string]int)
+	var textLen int
+	for _, c := range d.Cards {
+		t := c.TypeLine()
+		if t.Is("Action") {
+			s.Actions++
+		}
+		if t.Is("Thing") {
+			s.Things++
+		}
+		if !t.Is("Action") && !t.Is("Thing") {
+			s.Other++
+		}
+		for _, sub := range t.Subtypes {
+			subtypes[sub]++
+		}
+		if c.Image != "" {
+			s.WithImages++
+		}
+		if cr := c.CreatorText(); cr != "" {
+			creators[cr]++
+		}
+		for _, kw := range c.Keywords() {
+			keywords[kw]++
+		}
+		n := utf8.RuneCountInString(c.PlainText())
+		textLen += n
+		s.TextLengths.add(n)
+	}
+	if s.Cards > 0 {
+		s.AvgTextLen = float64(textLen) / float64(s.Cards)
+	}
+	s.Creators = counts(creators)
+	s.Subtypes = counts(subtypes)
+	s.Keywords = counts(keywords)
+	return s
+}
+
+// counts returns the Counts of m in decreasing order,
+// with equal counts in order of name.
+func counts(m map[string]int) []Count {
+	c := make([]Count, 0, len(m))
+	for name, n := range m {
+		c = append(c, Count{name, n})
+	}
+	sort.Slice(c, func(i, j int) bool {
+		if c[i].N != c[j].N {
+			return c[i].N > c[j].N
+		}
+		return c[i].Name < c[j].Name
+	})
+	return c
+}
+
+// ActionThingRatio returns the ratio of Actions to Things,
+// or 0 if there are no Things.
+func (s DeckStats) ActionThingRatio() float64 {
+	if s.Things == 0 {
+		return 0
+	}
+	return float64(s.Actions) / float64(s.Things)
+}
+
+// WriteJSON writes s to w as JSON.
+func (s DeckStats) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(struct {
+		DeckStats
+		ActionThingRatio float64 `json:"actionThingRatio"`
+	}{s, s.ActionThingRatio()})
+}
+
+// maxTop is the number of most common creators, subtypes, and keywords
+// that WriteText and WriteHTML write.
+const maxTop = 10
+
+// WriteText writes s to w as a plain text report.
+func (s DeckStats) WriteText(w io.Writer) error {
+	var b strings.Builder
+	if s.Name != "" {
+		fmt.Fprintf(&b, "%s\n\n", s.Name)
+	}
+	fmt.Fprintf(&b, "Cards:         %d\n", s.Cards)
+	fmt.Fprintf(&b, "Actions:       %d\n", s.Actions)
+	fmt.Fprintf(&b, "Things:        %d\n", s.Things)
+	fmt.Fprintf(&b, "Other:         %d\n", s.Other)
+	fmt.Fprintf(&b, "Action/Thing:  %.2f\n", s.ActionThingRatio())
+	fmt.Fprintf(&b, "With images:   %d\n", s.WithImages)
+	fmt.Fprintf(&b, "Avg text len:  %.1f\n", s.AvgTextLen)
+	for _, sec := range []struct {
+		title string
+		c     []Count
+	}{
+		{"Creators", s.Creators},
+		{"Subtypes", s.Subtypes},
+		{"Keywords", s.Keywords},
+	} {
+		if len(sec.c) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:\n", sec.title)
+		writeBars(&b, top(sec.c))
+	}
+	if len(s.TextLengths.Counts) > 0 {
+		fmt.Fprintf(&b, "\nText lengths:\n")
+		writeBars(&b, s.TextLengths.Buckets())
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// maxBar is the length of the longest bar that WriteText writes.
+const maxBar = 40
+
+// writeBars writes each element of c to b with a bar of "#" characters
+// whose length is proportional to its count, scaled so that the largest
+// count has a bar of maxBar characters. A nonzero count has a bar of at
+// least one character.
+func writeBars(b *strings.Builder, c []Count) {
+	max := maxCount(c)
+	for _, v := range c {
+		n := 0
+		if max > 0 {
+			n = (v.N*maxBar + max - 1) / max
+		}
+		fmt.Fprintf(b, "  %-20s %4d %s\n", v.Name, v.N, strings.Repeat("#", n))
+	}
+}
+
+// maxCount returns the largest count in c, or 0 if c is empty.
+func maxCount(c []Count) int {
+	var m int
+	for _, v := range c {
+		if v.N > m {
+			m = v.N
+		}
+	}
+	return m
+}
+
+// Buckets returns a Count of each bucket of h, named by its range of values.
+func (h Histogram) Buckets() []Count {
+	c := make([]Count, len(h.Counts))
+	for i, n := range h.Counts {
+		c[i] = Count{fmt.Sprintf("%d-%d", i*h.Width, (i+1)*h.Width-1), n}
+	}
+	return c
+}
+
+// top returns the first maxTop elements of c.
+func top(c []Count) []Count {
+	if len(c) > maxTop {
+		return c[:maxTop]
+	}
+	return c
+}
+
+var statsTemplate = template.Must(template.New("stats").Funcs(template.FuncMap{
+	"top": top,
+	"pct": func(n, max int) int {
+		if max == 0 {
+			return 0
+		}
+		return 100 * n / max
+	},
+	"max": maxCount,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} statistics</title>
+<style>
+table { border-collapse: collapse; }
+td, th { padding: 0.2em 0.6em; text-align: left; }
+.bar { background: #369; height: 1em; }
+</style>
+</head>
+<body>
+<h1>{{.Name}} statistics</h1>
+<table>
+<tr><th>Cards</th><td>{{.Cards}}</td></tr>
+<tr><th>Actions</th><td>{{.Actions}}</td></tr>
+<tr><th>Things</th><td>{{.Things}}</td></tr>
+<tr><th>Other</th><td>{{.Other}}</td></tr>
+<tr><th>Action/Thing ratio</th><td>{{printf "%.2f" .ActionThingRatio}}</td></tr>
+<tr><th>With images</th><td>{{.WithImages}}</td></tr>
+<tr><th>Average text length</th><td>{{printf "%.1f" .AvgTextLen}}</td></tr>
+</table>
+{{define "counts"}}{{$max := max .}}<table>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.N}}</td><td style="width: 20em"><div class="bar" style="width: {{pct .N $max}}%"></div></td></tr>
+{{end}}</table>{{end}}
+{{with .Creators}}<h2>Creators</h2>
+{{template "counts" top .}}{{end}}
+{{with .Subtypes}}<h2>Subtypes</h2>
+{{template "counts" top .}}{{end}}
+{{with .Keywords}}<h2>Keywords</h2>
+{{template "counts" top .}}{{end}}
+{{with .TextLengths.Counts}}<h2>Text lengths</h2>
+{{template "counts" $.TextLengths.Buckets}}{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes s to w as an HTML page.
+func (s DeckStats) WriteHTML(w io.Writer) error {
+	return statsTemplate.Execute(w, s)
+}
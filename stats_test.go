@@ -0,0 +1,103 @@
+package dvorak
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"kr.dev/diff"
+)
+
+var statsDeck = Deck{
+	Name: "Test",
+	Cards: Parse([]byte(`{{card|title=Moon|type=Thing - Moon|text=Draw a card. Destroy a Thing.|image=Moon.png|creator=Alice}}
+{{card|title=Zap|type=Action|text=` + strings.Repeat("x", 60) + `|creator=Alice}}
+{{card|title=Cheese|type=Thing - Moon Cheese|creator=Bob}}
+{{card|title=Letter|type=Letter|text=Draw a card.}}`)),
+}
+
+func TestStats(t *testing.T) {
+	got := Stats(statsDeck)
+	want := DeckStats{
+		Name:       "Test",
+		Cards:      4,
+		Actions:    1,
+		Things:     2,
+		Other:      1,
+		WithImages: 1,
+		AvgTextLen: (29 + 60 + 12) / 4.0,
+		Creators:   []Count{{"Alice", 2}, {"Bob", 1}},
+		Subtypes:   []Count{{"Moon", 2}, {"Cheese", 1}},
+		Keywords:   []Count{{"draw", 2}, {"destroy", 1}},
+		TextLengths: Histogram{
+			Width:  50,
+			Counts: []int{3, 1},
+		},
+	}
+	diff.Test(t, t.Errorf, got, want)
+	if r := got.ActionThingRatio(); r != 0.5 {
+		t.Errorf("ActionThingRatio: got %v, want 0.5", r)
+	}
+}
+
+func TestStatsEmpty(t *testing.T) {
+	s := Stats(Deck{})
+	if s.AvgTextLen != 0 || s.ActionThingRatio() != 0 {
+		t.Errorf("Stats(Deck{}): got AvgTextLen %v, ActionThingRatio %v; want 0, 0", s.AvgTextLen, s.ActionThingRatio())
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	h := Histogram{Width: 10, Counts: []int{2, 0, 1}}
+	want := []Count{{"0-9", 2}, {"10-19", 0}, {"20-29", 1}}
+	diff.Test(t, t.Errorf, h.Buckets(), want)
+}
+
+func TestWriteBars(t *testing.T) {
+	var b strings.Builder
+	writeBars(&b, []Count{{"a", 1000}, {"b", 500}, {"c", 1}, {"d", 0}})
+	want := "  a                    1000 " + strings.Repeat("#", maxBar) + "\n" +
+		"  b                     500 " + strings.Repeat("#", maxBar/2) + "\n" +
+		"  c                       1 #\n" +
+		"  d                       0 \n"
+	if got := b.String(); got != want {
+		t.Errorf("writeBars: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestStatsWrite(t *testing.T) {
+	s := Stats(statsDeck)
+
+	var b bytes.Buffer
+	if err := s.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Cards:         4\n", "Action/Thing:  0.50\n", "  Alice                   2 " + strings.Repeat("#", maxBar) + "\n", "  50-99                   1 " + strings.Repeat("#", 14) + "\n"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteText: missing %q in\n%s", want, b.String())
+		}
+	}
+
+	b.Reset()
+	if err := s.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["actionThingRatio"] != 0.5 || m["cards"] != 4.0 {
+		t.Errorf("WriteJSON: got %s", b.Bytes())
+	}
+
+	b.Reset()
+	if err := s.WriteHTML(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<h1>Test statistics</h1>", "<td>Alice</td><td>2</td>", "<h2>Text lengths</h2>"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteHTML: missing %q in\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package dvorak
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// An ImageStore is a local directory of card images. Each image is stored
+// in a file named by the SHA-1 hash of its contents, so images shared by
+// several cards or decks are stored once. An index maps Card.Image values
+// to stored images so that they can be found without querying the wiki.
+type ImageStore struct {
+	// Dir is the directory that contains the store.
+	Dir string
+
+	mu sync.Mutex
+}
+
+// storedImage is an entry of an ImageStore's index.
+type storedImage struct {
+	SHA1 string `json:"sha1"`
+
+	// Ext is the file extension of the image, including the dot.
+	Ext string `json:"ext"`
+}
+
+// indexFile is the name of the index within an ImageStore's directory.
+const indexFile = "index.json"
+
+// fetchWorkers is the number of images that FetchImages downloads at once.
+const fetchWorkers = 4
+
+// valid reports whether im names a file within its store: its SHA1 is
+// exactly 40 lowercase hexadecimal digits, and its Ext is empty or a dot
+// followed by lowercase letters and digits.
+func (im storedImage) valid() bool {
+	if len(im.SHA1) != 2*sha1.Size || strings.ToLower(im.SHA1) != im.SHA1 {
+		return false
+	}
+	if _, err := hex.DecodeString(im.SHA1); err != nil {
+		return false
+	}
+	if im.Ext == "" {
+		return true
+	}
+	for i, r := range im.Ext {
+		if (i == 0) != (r == '.') || (i > 0 && !('a' <= r && r <= 'z' || '0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return len(im.Ext) > 1
+}
+
+// path returns the name of the file that stores im, which must be valid.
+func (s *ImageStore) path(im storedImage) string {
+	return filepath.Join(s.Dir, im.SHA1[:2], im.SHA1+im.Ext)
+}
+
+// readIndex returns the index of s.
+func (s *ImageStore) readIndex() (map[string]storedImage, error) {
+	index := make(map[string]storedImage)
+	b, err := os.ReadFile(filepath.Join(s.Dir, indexFile))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("%s: %v", indexFile, err)
+	}
+	return index, nil
+}
+
+// addIndex adds entries to the index of s.
+func (s *ImageStore) addIndex(entries map[string]storedImage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index, err := s.readIndex()
+	if err != nil {
+		return err
+	}
+	for name, im := range entries {
+		index[name] = im
+	}
+	b, err := json.MarshalIndent(index, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.Dir, indexFile), b)
+}
+
+// Paths returns the names of the stored files of the images of cards,
+// keyed by Card.Image. Images that are not in the store are omitted.
+// Paths does not access the network.
+func (s *ImageStore) Paths(cards []Card) (map[string]string, error) {
+	s.mu.Lock()
+	index, err := s.readIndex()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]string)
+	for _, c := range cards {
+		im, ok := index[c.Image]
+		if !ok || !im.valid() {
+			continue
+		}
+		if p := s.path(im); fileExists(p) {
+			paths[c.Image] = p
+		}
+	}
+	return paths, nil
+}
+
+// FetchImages downloads the images of cards into s and returns the names
+// of their stored files, keyed by Card.Image. It uses DefaultClient.
+func FetchImages(cards []Card, s *ImageStore) (map[string]string, error) {
+	return DefaultClient.FetchImages(cards, s)
+}
+
+// FetchImages downloads the images of cards into s and returns the names
+// of their stored files, keyed by Card.Image. Images that are already
+// stored are not downloaded again. Each downloaded image is verified
+// against the SHA-1 hash that the wiki reports for it.
+//
+// Images that are missing from the wiki are omitted. If any image cannot
+// be downloaded, FetchImages returns the images that were stored along
+// with an error.
+func (c *Client) FetchImages(cards []Card, s *ImageStore) (map[string]string, error) {
+	infos, err := c.ImageURLs(cards)
+	if err != nil {
+		return nil, err
+	}
+
+	type job struct {
+		name string
+		info ImageInfo
+	}
+	jobs := make(chan job)
+	var (
+		mu       sync.Mutex
+		paths    = make(map[string]string)
+		entries  = make(map[string]storedImage)
+		firstErr error
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < fetchWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				im, err := c.storeImage(s, j.info)
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("image %q: %v", j.name, err)
+					}
+				} else {
+					paths[j.name] = s.path(im)
+					entries[j.name] = im
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for name, info := range infos {
+		if info.URL != "" && info.SHA1 != "" {
+			jobs <- job{name, info}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(entries) > 0 {
+		if err := s.addIndex(entries); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return paths, firstErr
+}
+
+// storeImage downloads the image described by info into s
+// unless it is already stored.
+func (c *Client) storeImage(s *ImageStore, info ImageInfo) (storedImage, error) {
+	im := storedImage{
+		SHA1: strings.ToLower(info.SHA1),
+		Ext:  strings.ToLower(path.Ext(info.Name)),
+	}
+	if !im.valid() {
+		return storedImage{}, fmt.Errorf("invalid SHA-1 %q or file extension %q", info.SHA1, im.Ext)
+	}
+	p := s.path(im)
+	if fileExists(p) {
+		return im, nil
+	}
+	b, err := c.Download(info.URL)
+	if err != nil {
+		return storedImage{}, err
+	}
+	sum := sha1.Sum(b)
+	if got := hex.EncodeToString(sum[:]); got != im.SHA1 {
+		return storedImage{}, fmt.Errorf("%v: SHA-1 %s, want %s", info.URL, got, im.SHA1)
+	}
+	return im, writeFileAtomic(p, b)
+}
+
+// Download returns the body of the resource at url, such as an image
+// file on the wiki, with the Client's rate limit and retries.
+func (c *Client) Download(url string) ([]byte, error) {
+	return c.readPage(url)
+}
+
+// writeFileAtomic writes b to the named file by renaming a temporary file,
+// so that readers never see a partially written file.
+func writeFileAtomic(name string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), name); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// fileExists reports whether the named file exists.
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
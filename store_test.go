@@ -0,0 +1,167 @@
+package dvorak
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dkmccandless/dvorak/dvoraktest"
+)
+
+func TestFetchImages(t *testing.T) {
+	w := dvoraktest.NewServer()
+	defer w.Close()
+	w.SetFile("Fish.png", dvoraktest.File{Content: []byte("fish")})
+	w.SetFile("Moon.jpg", dvoraktest.File{Content: []byte("moon")})
+	c := &Client{HTTPClient: w.Client()}
+	cards := Parse([]byte(`{{card|title=A|image=Fish.png}}{{card|title=B|image=Moon.jpg}}
+{{card|title=C|image=Fish.png}}{{card|title=D|image=Gone.png}}{{card|title=E}}`))
+	s := &ImageStore{Dir: t.TempDir()}
+
+	paths, err := c.FetchImages(cards, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("FetchImages: got %d paths, want 2: %v", len(paths), paths)
+	}
+	for name, want := range map[string]string{"Fish.png": "fish", "Moon.jpg": "moon"} {
+		b, err := os.ReadFile(paths[name])
+		if err != nil || string(b) != want {
+			t.Errorf("%s: got %q, %v; want %q", name, b, err, want)
+		}
+	}
+	if ext := filepath.Ext(paths["Moon.jpg"]); ext != ".jpg" {
+		t.Errorf("Moon.jpg: got extension %q, want .jpg", ext)
+	}
+	// One imageinfo query and two downloads.
+	if n := w.Requests(); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+
+	// Stored images are not downloaded again.
+	if _, err := c.FetchImages(cards, s); err != nil {
+		t.Fatal(err)
+	}
+	if n := w.Requests(); n != 4 {
+		t.Errorf("after refetching: got %d requests, want 4", n)
+	}
+
+	// Paths finds stored images offline.
+	offline := &ImageStore{Dir: s.Dir}
+	got, err := offline.Paths(cards)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, p := range paths {
+		if got[name] != p {
+			t.Errorf("Paths()[%q]: got %q, want %q", name, got[name], p)
+		}
+	}
+}
+
+func TestFetchImagesFakeWiki(t *testing.T) {
+	w := dvoraktest.NewServer()
+	defer w.Close()
+	w.SetFile("Golden fish.png", dvoraktest.File{Content: []byte("fish")})
+	w.SetRedirect("File:Old fish.png", "File:Golden fish.png")
+	c := &Client{HTTPClient: w.Client()}
+	cards := Parse([]byte(`{{card|title=A|image=golden_fish.png}}{{card|title=B|image=Old fish.png}}{{card|title=C|image=Gone.png}}`))
+	s := &ImageStore{Dir: t.TempDir()}
+
+	paths, err := c.FetchImages(cards, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths["golden_fish.png"] != paths["Old fish.png"] {
+		t.Fatalf("FetchImages: got %v, want one stored file for two names", paths)
+	}
+	if b, err := os.ReadFile(paths["Old fish.png"]); err != nil || string(b) != "fish" {
+		t.Errorf("got %q, %v; want %q", b, err, "fish")
+	}
+}
+
+func TestFetchImagesBadHash(t *testing.T) {
+	w := dvoraktest.NewServer()
+	defer w.Close()
+	w.SetFile("Fish.png", dvoraktest.File{Content: []byte("fish"), SHA1: strings.Repeat("0", 40)})
+	w.SetFile("Moon.jpg", dvoraktest.File{Content: []byte("moon")})
+	c := &Client{HTTPClient: w.Client()}
+	cards := Parse([]byte(`{{card|title=A|image=Fish.png}}{{card|title=B|image=Moon.jpg}}`))
+	s := &ImageStore{Dir: t.TempDir()}
+	paths, err := c.FetchImages(cards, s)
+	if err == nil {
+		t.Errorf("FetchImages: got nil error")
+	}
+	if _, ok := paths["Fish.png"]; ok {
+		t.Errorf("FetchImages stored Fish.png despite its hash mismatch")
+	}
+	if _, ok := paths["Moon.jpg"]; !ok {
+		t.Errorf("FetchImages did not store Moon.jpg")
+	}
+}
+
+func TestStoredImageValid(t *testing.T) {
+	sum := strings.Repeat("0123456789abcdef", 3)[:40]
+	for _, tt := range []struct {
+		im   storedImage
+		want bool
+	}{
+		{storedImage{SHA1: sum, Ext: ".png"}, true},
+		{storedImage{SHA1: sum}, true},
+		{storedImage{SHA1: strings.ToUpper(sum), Ext: ".png"}, false},
+		{storedImage{SHA1: sum[:39], Ext: ".png"}, false},
+		{storedImage{SHA1: sum + "0", Ext: ".png"}, false},
+		{storedImage{SHA1: "../../../../../../../../../../etc/passwd", Ext: ".png"}, false},
+		{storedImage{SHA1: strings.Repeat("g", 40), Ext: ".png"}, false},
+		{storedImage{SHA1: sum, Ext: "."}, false},
+		{storedImage{SHA1: sum, Ext: ".png/.."}, false},
+		{storedImage{SHA1: sum, Ext: "png"}, false},
+	} {
+		if got := tt.im.valid(); got != tt.want {
+			t.Errorf("%+v.valid() = %v, want %v", tt.im, got, tt.want)
+		}
+	}
+}
+
+func TestStoreImageInvalid(t *testing.T) {
+	s := &ImageStore{Dir: t.TempDir()}
+	c := &Client{HTTPClient: &http.Client{Transport: failTransport{t}}}
+	for _, sum := range []string{"../" + strings.Repeat("0", 37), strings.Repeat("z", 40), "0"} {
+		if _, err := c.storeImage(s, ImageInfo{Name: "Fish.png", URL: "https://dvorakgame.co.uk/images/Fish.png", SHA1: sum}); err == nil {
+			t.Errorf("storeImage with SHA-1 %q: got nil error", sum)
+		}
+	}
+}
+
+// failTransport is an http.RoundTripper that fails the test
+// if it is used.
+type failTransport struct{ t *testing.T }
+
+func (ft failTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ft.t.Errorf("unexpected request for %v", r.URL)
+	return nil, errors.New("unexpected request")
+}
+
+func TestImageStorePathsInvalidIndex(t *testing.T) {
+	s := &ImageStore{Dir: t.TempDir()}
+	index := `{"Fish.png": {"sha1": "../../../../../../../../../../etc/passwd", "ext": ""}}`
+	if err := os.WriteFile(filepath.Join(s.Dir, indexFile), []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := s.Paths(Parse([]byte("{{card|title=A|image=Fish.png}}")))
+	if err != nil || len(paths) != 0 {
+		t.Errorf("Paths: got %v, %v; want empty, nil", paths, err)
+	}
+}
+
+func TestImageStorePathsEmpty(t *testing.T) {
+	s := &ImageStore{Dir: filepath.Join(t.TempDir(), "none")}
+	paths, err := s.Paths(Parse([]byte("{{card|title=A|image=Fish.png}}")))
+	if err != nil || len(paths) != 0 {
+		t.Errorf("Paths: got %v, %v; want empty, nil", paths, err)
+	}
+}
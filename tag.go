@@ -18,7 +18,9 @@ func escapeNonTags(s string) string {
 	z := html.NewTokenizer(strings.NewReader(s))
 	for {
 		tt := z.Next()
-		switch raw := z.Raw(); tt {
+		// Token unescapes attribute values in the tokenizer's buffer,
+		// so raw must be copied before it is called.
+		switch raw := append([]byte(nil), z.Raw()...); tt {
 		case html.ErrorToken:
 			return b.String()
 		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
@@ -20,6 +20,10 @@ func TestEscapeInvalidTags(t *testing.T) {
 			"<font color=FFD700>Golden Text</font>",
 			"<font color=FFD700>Golden Text</font>",
 		},
+		{
+			`<span title="Fish &amp; Chips">Meal</span>`,
+			`<span title="Fish &amp; Chips">Meal</span>`,
+		},
 		{
 			"Replace <metal> with the type of metal",
 			"Replace &lt;metal&gt; with the type of metal",
@@ -0,0 +1,212 @@
+package dvorak
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// TitleText returns the text of the card's title.
+func (c Card) TitleText() string { return plainText(c.Title) }
+
+// TypeText returns the text of the card's type.
+func (c Card) TypeText() string { return plainText(c.Type) }
+
+// PlainText returns the card's rule text without markup.
+// Line breaks and paragraphs are preserved as newlines
+// and list items are prefixed with "- ".
+func (c Card) PlainText() string { return plainText(c.Text) }
+
+// Markdown returns the card's rule text formatted as Markdown.
+func (c Card) Markdown() string { return markdown(c.Text) }
+
+// FlavorPlainText returns the card's flavor text without markup.
+func (c Card) FlavorPlainText() string { return plainText(c.FlavorText) }
+
+// CreatorText returns the text of the card's creator.
+func (c Card) CreatorText() string { return plainText(c.Creator) }
+
+// plainText returns the text content of frag formatted as plain text.
+func plainText(frag []*html.Node) string {
+	return flatten(frag, false)
+}
+
+// markdown returns the text content of frag formatted as Markdown.
+func markdown(frag []*html.Node) string {
+	return flatten(frag, true)
+}
+
+var (
+	spaceRun   = regexp.MustCompile(`[ \t\r\n\f]+`)
+	lineSpace  = regexp.MustCompile(`[ \t]*\n[ \t]*`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	mdSpecial  = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`,
+		"<", `\<`, ">", `\>`, "#", `\#`)
+	mdLineStart = regexp.MustCompile(`^ *(?:[-+=]|[0-9]+[.)])`)
+)
+
+// mdEscapeStart escapes a list or heading marker at the start of s, which
+// may begin a line of the Markdown output. Markers in the middle of a line
+// are rendered unchanged, escaped or not.
+func mdEscapeStart(s string) string {
+	loc := mdLineStart.FindStringIndex(s)
+	if loc == nil {
+		return s
+	}
+	return s[:loc[1]-1] + `\` + s[loc[1]-1:]
+}
+
+// flatten renders frag as text, or as Markdown if md is true.
+func flatten(frag []*html.Node, md bool) string {
+	var b strings.Builder
+	for _, n := range frag {
+		b.WriteString(flattenNode(n, md))
+	}
+	s := lineSpace.ReplaceAllString(b.String(), "\n")
+	s = blankLines.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// flattenNode renders n and its descendants.
+func flattenNode(n *html.Node, md bool) string {
+	switch n.Type {
+	case html.TextNode:
+		s := spaceRun.ReplaceAllString(n.Data, " ")
+		if md {
+			s = mdEscapeStart(mdSpecial.Replace(s))
+		}
+		return s
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	var b strings.Builder
+	item := 0
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		s := flattenNode(c, md)
+		if c.Type == html.ElementNode && c.Data == "li" {
+			item++
+			marker := "- "
+			if md && n.Data == "ol" {
+				marker = strconv.Itoa(item) + ". "
+			}
+			s = "\n" + marker + strings.TrimSpace(s)
+		}
+		b.WriteString(s)
+	}
+	s := b.String()
+
+	switch n.Data {
+	case "br":
+		return "\n"
+	case "hr":
+		if md {
+			return "\n\n---\n\n"
+		}
+		return "\n\n"
+	case "p", "div", "blockquote", "center", "table":
+		return "\n\n" + s + "\n\n"
+	case "ul", "ol":
+		// Each item begins with a newline.
+		return s + "\n"
+	case "dl", "tr":
+		return "\n" + s + "\n"
+	case "dt", "dd":
+		return "\n" + s
+	case "td", "th":
+		return s + " "
+	case "b", "strong":
+		if md {
+			return emphasize(s, "**")
+		}
+	case "i", "em":
+		if md {
+			return emphasize(s, "*")
+		}
+	case "script", "style":
+		return ""
+	}
+	return s
+}
+
+// emphasize surrounds s with marker, keeping any leading and trailing
+// whitespace outside of the markers as Markdown requires.
+func emphasize(s, marker string) string {
+	t := strings.TrimSpace(s)
+	if t == "" {
+		return s
+	}
+	i := strings.Index(s, t)
+	return s[:i] + marker + t + marker + s[i+len(t):]
+}
+
+// wikitext returns frag formatted as wikitext that parseWikitext parses
+// to an equivalent fragment. Bold and italic elements are written as wiki
+// markup and other elements as HTML. Characters that delimit templates
+// and their parameters are escaped, so that the result can be used as
+// the value of a card template parameter.
+func wikitext(frag []*html.Node) string {
+	var b strings.Builder
+	for _, n := range frag {
+		writeWikitext(&b, n)
+	}
+	return b.String()
+}
+
+var (
+	wikiEscaper     = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	templateEscaper = strings.NewReplacer("|", "&#124;", "=", "&#61;", "{", "&#123;", "}", "&#125;")
+)
+
+// writeWikitext writes n and its descendants to b as wikitext.
+func writeWikitext(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		s := templateEscaper.Replace(wikiEscaper.Replace(n.Data))
+		// Apostrophes are only special in runs or next to markup.
+		if strings.Contains(s, "''") || strings.HasPrefix(s, "'") || strings.HasSuffix(s, "'") {
+			s = strings.ReplaceAll(s, "'", "&#39;")
+		}
+		b.WriteString(s)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	var open, close string
+	switch {
+	case n.Data == "b" && len(n.Attr) == 0:
+		open, close = "'''", "'''"
+	case n.Data == "i" && len(n.Attr) == 0:
+		open, close = "''", "''"
+	default:
+		var tag strings.Builder
+		tag.WriteString("<" + n.Data)
+		for _, a := range n.Attr {
+			tag.WriteString(" " + a.Key + `="` + templateEscaper.Replace(html.EscapeString(a.Val)) + `"`)
+		}
+		tag.WriteString(">")
+		open = tag.String()
+		if voidElements[n.Data] {
+			b.WriteString(open)
+			return
+		}
+		close = "</" + n.Data + ">"
+	}
+	b.WriteString(open)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeWikitext(b, c)
+	}
+	b.WriteString(close)
+}
+
+// voidElements is the set of HTML elements that have no end tag.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"source": true, "track": true, "wbr": true,
+}
@@ -0,0 +1,88 @@
+package dvorak
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+	for _, tt := range []struct{ value, want string }{
+		{"", ""},
+		{"Draw a card.", "Draw a card."},
+		{"'''Action:''' Draw a card.", "Action: Draw a card."},
+		{"Destroy target Thing.<br>Draw a card.", "Destroy target Thing.\nDraw a card."},
+		{"Destroy target Thing. <br/> Draw a card.", "Destroy target Thing.\nDraw a card."},
+		{"Fish &amp; Chips", "Fish & Chips"},
+		{"Replace <metal> with a metal", "Replace <metal> with a metal"},
+		{"<p>One</p><p>Two</p>", "One\n\nTwo"},
+		{"Choose one:<ul><li>Draw</li><li>Discard</li></ul>", "Choose one:\n- Draw\n- Discard"},
+		{"a\n  b", "a b"},
+		{"<font color=FFD700>Golden</font> Text", "Golden Text"},
+	} {
+		if got := plainText(parseWikitext(tt.value)); got != tt.want {
+			t.Errorf("plainText(%q): got %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	for _, tt := range []struct{ value, want string }{
+		{"", ""},
+		{"'''Action:''' Draw a card.", "**Action:** Draw a card."},
+		{"''Italics'' '''Bold''' '''''Both'''''", "*Italics* **Bold** ***Both***"},
+		{"<b>Action: </b>Draw a card.", "**Action:** Draw a card."},
+		{"Destroy target Thing.<br>Draw a card.", "Destroy target Thing.\nDraw a card."},
+		{"Choose one:<ol><li>Draw</li><li>''Discard''</li></ol>", "Choose one:\n1. Draw\n2. *Discard*"},
+		{"2 * 3 = 6_", `2 \* 3 = 6\_`},
+		{"Rule<hr>Flavor", "Rule\n\n---\n\nFlavor"},
+		{"Replace &lt;metal&gt; with a metal", `Replace \<metal\> with a metal`},
+		{"#1 fan", `\#1 fan`},
+		{"- Not a list", `\- Not a list`},
+		{"Draw.<br>+1 card", "Draw.\n\\+1 card"},
+		{"Draw.<br>1. Win", "Draw.\n1\\. Win"},
+		{"Draw.<br>==Not a heading==", "Draw.\n\\==Not a heading=="},
+		{"Score 10-2.", "Score 10-2."},
+	} {
+		if got := markdown(parseWikitext(tt.value)); got != tt.want {
+			t.Errorf("markdown(%q): got %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCardText(t *testing.T) {
+	c := Parse([]byte("{{card|title='''Golden''' Fish|type=Thing|text=Draw a card.<br>''Discard'' a card.|creator=[[User:Binarius|Binarius]]|flavortext=Blub.}}"))[0]
+	for _, tt := range []struct{ name, got, want string }{
+		{"TitleText", c.TitleText(), "Golden Fish"},
+		{"TypeText", c.TypeText(), "Thing"},
+		{"PlainText", c.PlainText(), "Draw a card.\nDiscard a card."},
+		{"Markdown", c.Markdown(), "Draw a card.\n*Discard* a card."},
+		{"FlavorPlainText", c.FlavorPlainText(), "Blub."},
+		{"CreatorText", c.CreatorText(), "Binarius"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestWikitext(t *testing.T) {
+	for _, tt := range []struct{ value, want string }{
+		{"", ""},
+		{"Draw a card.", "Draw a card."},
+		{"''Italics'' '''Bold''' '''''Both'''''", "''Italics'' '''Bold''' '''''Both'''''"},
+		{"<b>Action:</b> Draw.<br/>Discard.", "'''Action:''' Draw.<br>Discard."},
+		{"Fish &amp; Chips", "Fish &amp; Chips"},
+		{"Replace <metal> with a metal", "Replace &lt;metal&gt; with a metal"},
+		{"Bob's '''fish'''", "Bob's '''fish'''"},
+		{"<b>'Quoted'</b>", "'''&#39;Quoted&#39;'''"},
+		{"<font color=FFD700>Golden</font>", `<font color="FFD700">Golden</font>`},
+	} {
+		frag := parseWikitext(tt.value)
+		got := wikitext(frag)
+		if got != tt.want {
+			t.Errorf("wikitext(%q): got %q, want %q", tt.value, got, tt.want)
+		}
+		if dump(parseWikitext(got)) != dump(frag) {
+			t.Errorf("parseWikitext(wikitext(%q)): got %v, want %v",
+				tt.value, dump(parseWikitext(got)), dump(frag),
+			)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package dvorak
+
+import (
+	"strings"
+	"unicode"
+)
+
+// supertypes lists the recognized card supertypes.
+var supertypes = []string{"Action", "Thing"}
+
+// CardType is a card's parsed type line, such as "Action - Song"
+// or "Legendary Action/Thing - Moon Cheese".
+type CardType struct {
+	// Supertypes lists the recognized supertypes of the card,
+	// "Action" and "Thing", that appear before any dash.
+	Supertypes []string
+
+	// Subtypes lists the words that appear after a dash.
+	Subtypes []string
+
+	// Remainder is the text before any dash that is not a supertype.
+	Remainder string
+}
+
+// ParseType parses a card type line.
+// Supertypes are recognized without regard to case. A hyphen separated by
+// spaces, an en dash, or an em dash separates the subtypes.
+func ParseType(s string) CardType {
+	var t CardType
+	before, after := splitType(s)
+
+	var rest []string
+	for _, w := range strings.FieldsFunc(before, isTypeSeparator) {
+		if st := supertype(w); st != "" {
+			t.Supertypes = append(t.Supertypes, st)
+		} else {
+			rest = append(rest, w)
+		}
+	}
+	t.Remainder = strings.Join(rest, " ")
+	if subs := strings.FieldsFunc(after, isTypeSeparator); len(subs) > 0 {
+		t.Subtypes = subs
+	}
+	return t
+}
+
+// splitType splits a type line at its first dash.
+func splitType(s string) (before, after string) {
+	for _, sep := range []string{" - ", "–", "—"} {
+		if i := strings.Index(s, sep); i != -1 {
+			return s[:i], s[i+len(sep):]
+		}
+	}
+	return s, ""
+}
+
+// isTypeSeparator reports whether r separates the words of a type line.
+func isTypeSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == '/' || r == ',' || r == '&'
+}
+
+// supertype returns the canonical form of w if it is a supertype,
+// or the empty string otherwise.
+func supertype(w string) string {
+	for _, st := range supertypes {
+		if strings.EqualFold(w, st) {
+			return st
+		}
+	}
+	return ""
+}
+
+// Is reports whether t has the supertype st, without regard to case.
+func (t CardType) Is(st string) bool {
+	return containsFold(t.Supertypes, st)
+}
+
+// Has reports whether t has the subtype sub, without regard to case.
+func (t CardType) Has(sub string) bool {
+	return containsFold(t.Subtypes, sub)
+}
+
+// containsFold reports whether a contains s without regard to case.
+func containsFold(a []string, s string) bool {
+	for _, v := range a {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the type line in a normalized form.
+func (t CardType) String() string {
+	s := strings.Join(t.Supertypes, "/")
+	if t.Remainder != "" {
+		if s != "" {
+			s = t.Remainder + " " + s
+		} else {
+			s = t.Remainder
+		}
+	}
+	if len(t.Subtypes) > 0 {
+		s += " - " + strings.Join(t.Subtypes, " ")
+	}
+	return s
+}
+
+// TypeLine returns the card's parsed type line.
+func (c Card) TypeLine() CardType { return ParseType(c.TypeText()) }
+
+// ColorRules maps lowercase supertypes and subtypes
+// to default card header background colors.
+type ColorRules map[string]Color
+
+// DefaultColorRules are the default header background colors of the wiki's
+// card template.
+var DefaultColorRules = ColorRules{
+	"action": actionRed,
+	"thing":  thingBlue,
+}
+
+// Color returns the default header background color of a card of type t.
+// The first subtype with a rule determines the color. Otherwise,
+// if all of t's supertypes with a rule have the same color, that color
+// is used, and if not, the color is gray.
+func (r ColorRules) Color(t CardType) Color {
+	for _, sub := range t.Subtypes {
+		if c, ok := r[strings.ToLower(sub)]; ok {
+			return c
+		}
+	}
+	var color Color
+	for _, st := range t.Supertypes {
+		c, ok := r[strings.ToLower(st)]
+		switch {
+		case !ok:
+		case color == "":
+			color = c
+		case color != c:
+			return otherGray
+		}
+	}
+	if color == "" {
+		return otherGray
+	}
+	return color
+}
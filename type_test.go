@@ -0,0 +1,94 @@
+package dvorak
+
+import (
+	"testing"
+
+	"kr.dev/diff"
+)
+
+func TestParseType(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want CardType
+		str  string
+	}{
+		{"", CardType{}, ""},
+		{"Action", CardType{Supertypes: []string{"Action"}}, "Action"},
+		{"thing", CardType{Supertypes: []string{"Thing"}}, "Thing"},
+		{"Letter", CardType{Remainder: "Letter"}, "Letter"},
+		{
+			"Action - Song",
+			CardType{Supertypes: []string{"Action"}, Subtypes: []string{"Song"}},
+			"Action - Song",
+		},
+		{
+			"Thing – Moon",
+			CardType{Supertypes: []string{"Thing"}, Subtypes: []string{"Moon"}},
+			"Thing - Moon",
+		},
+		{
+			"Action/Thing",
+			CardType{Supertypes: []string{"Action", "Thing"}},
+			"Action/Thing",
+		},
+		{
+			"Legendary Thing — Moon Cheese",
+			CardType{
+				Supertypes: []string{"Thing"},
+				Subtypes:   []string{"Moon", "Cheese"},
+				Remainder:  "Legendary",
+			},
+			"Legendary Thing - Moon Cheese",
+		},
+		{
+			"Action-Thing",
+			CardType{Remainder: "Action-Thing"},
+			"Action-Thing",
+		},
+	} {
+		got := ParseType(tt.s)
+		diff.Test(t, t.Errorf, got, tt.want)
+		if s := got.String(); s != tt.str {
+			t.Errorf("ParseType(%q).String(): got %q, want %q", tt.s, s, tt.str)
+		}
+	}
+}
+
+func TestCardTypeIsHas(t *testing.T) {
+	ct := ParseType("Thing - Moon")
+	if !ct.Is("thing") || ct.Is("Action") {
+		t.Errorf("%v.Is: got %v, %v; want true, false", ct, ct.Is("thing"), ct.Is("Action"))
+	}
+	if !ct.Has("moon") || ct.Has("Sun") {
+		t.Errorf("%v.Has: got %v, %v; want true, false", ct, ct.Has("moon"), ct.Has("Sun"))
+	}
+}
+
+func TestColorRules(t *testing.T) {
+	rules := ColorRules{"action": "900", "thing": "009", "moon": "FFD700"}
+	for _, tt := range []struct {
+		typ  string
+		want Color
+	}{
+		{"", otherGray},
+		{"Letter", otherGray},
+		{"Action", "900"},
+		{"Thing - Moon", "FFD700"},
+		{"Thing - Sun", "009"},
+		{"Action/Thing", otherGray},
+	} {
+		if got := rules.Color(ParseType(tt.typ)); got != tt.want {
+			t.Errorf("Color(%q): got %q, want %q", tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestParserColorRules(t *testing.T) {
+	p := &Parser{ColorRules: ColorRules{"song": "0F0"}}
+	cards := p.Parse([]byte("{{card|type=Action - Song}}{{card|type=Action}}{{card|type=Action|bgcolor=123}}"))
+	for i, want := range []Color{"0F0", otherGray, "123"} {
+		if cards[i].BGColor != want {
+			t.Errorf("card %d: got %q, want %q", i+1, cards[i].BGColor, want)
+		}
+	}
+}